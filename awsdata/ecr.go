@@ -7,7 +7,6 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/Masterminds/semver/v3"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -73,7 +72,7 @@ func awsProfiles() []string {
 
 func New(threads int) *AWS {
 	ret := AWS{
-		repos:    map[string][]*semver.Version{},
+		repos:    map[string][]string{},
 		services: map[string]*ecr.Client{},
 		ec2:      map[string]*ec2.Client{},
 		amis:     map[string][]ec2Types.Image{},
@@ -101,7 +100,7 @@ func New(threads int) *AWS {
 
 type AWS struct {
 	services map[string]*ecr.Client
-	repos    map[string][]*semver.Version
+	repos    map[string][]string
 	reposMux sync.Mutex
 	threads  int
 
@@ -109,7 +108,9 @@ type AWS struct {
 	amis map[string][]ec2Types.Image
 }
 
-func (a *AWS) Tags(repositoryName string) []*semver.Version {
+// Tags returns the tags of the ECR repository with the URI repositoryName,
+// found in any of the configured profiles.
+func (a *AWS) Tags(repositoryName string) []string {
 	if _, ok := a.repos[repositoryName]; ok {
 		return a.repos[repositoryName]
 	}
@@ -138,33 +139,35 @@ func (a *AWS) Tags(repositoryName string) []*semver.Version {
 
 	wg.Wait()
 
-	uniqueVersions := map[string]*semver.Version{}
-	for _, version := range a.repos[repositoryName] {
-		uniqueVersions[version.String()] = version
+	uniqueTags := map[string]struct{}{}
+	for _, tag := range a.repos[repositoryName] {
+		uniqueTags[tag] = struct{}{}
 	}
 
-	var uniqueVersionsSlice []*semver.Version
-	for version, data := range uniqueVersions {
+	uniqueTagsSlice := []string{}
+	for tag := range uniqueTags {
 		log.WithFields(log.Fields{
 			"repository": repositoryName,
-			"version":    version,
-		}).Trace("unique version")
+			"tag":        tag,
+		}).Trace("unique tag")
 
-		uniqueVersionsSlice = append(uniqueVersionsSlice, data)
+		uniqueTagsSlice = append(uniqueTagsSlice, tag)
 	}
 
-	a.repos[repositoryName] = uniqueVersionsSlice
-	sort.Sort(sort.Reverse(semver.Collection(a.repos[repositoryName])))
+	sort.Strings(uniqueTagsSlice)
+	a.repos[repositoryName] = uniqueTagsSlice
 
 	log.WithFields(log.Fields{
 		"repository": repositoryName,
-		"versions":   a.repos[repositoryName],
+		"tags":       a.repos[repositoryName],
 	}).Debug("retrieved tags")
 
 	return a.repos[repositoryName]
 }
 
-func ecrRepo(client *ecr.Client, repositoryName string) ([]*semver.Version, error) {
+// findRepository returns the repository of client with the URI
+// repositoryName, or nil.
+func findRepository(client *ecr.Client, repositoryName string) (*ecrTypes.Repository, error) {
 	paginator := ecr.NewDescribeRepositoriesPaginator(client, &ecr.DescribeRepositoriesInput{})
 
 	repos := []ecrTypes.Repository{}
@@ -176,7 +179,7 @@ func ecrRepo(client *ecr.Client, repositoryName string) ([]*semver.Version, erro
 				"page":  page,
 			}).Debug("Failed to describe repositories")
 
-			return []*semver.Version{}, fmt.Errorf("failed to describe repositories: %w", err)
+			return nil, fmt.Errorf("failed to describe repositories: %w", err)
 		}
 
 		log.WithFields(log.Fields{
@@ -187,59 +190,100 @@ func ecrRepo(client *ecr.Client, repositoryName string) ([]*semver.Version, erro
 		repos = append(repos, data.Repositories...)
 	}
 
-	for _, repo := range repos {
+	for i, repo := range repos {
 		log.WithFields(log.Fields{
 			"repo": *repo.RepositoryUri,
 		}).Trace("Repository")
 
-		if *repo.RepositoryUri != repositoryName {
-			continue
+		if *repo.RepositoryUri == repositoryName {
+			return &repos[i], nil
 		}
+	}
 
-		log.WithFields(log.Fields{
-			"repo": *repo.RepositoryUri,
-		}).Debug("Repository")
+	return nil, nil
+}
 
-		// describe image tags with pages
-		paginator := ecr.NewDescribeImagesPaginator(client, &ecr.DescribeImagesInput{
+// Digest returns the digest of the image tag of the ECR repository with the
+// URI repositoryName. Multi-platform images resolve to the digest of their
+// manifest list.
+func (a *AWS) Digest(repositoryName, tag string) (string, error) {
+	for profile, client := range a.services {
+		repo, err := findRepository(client, repositoryName)
+		if err != nil || repo == nil {
+			continue
+		}
+
+		output, err := client.BatchGetImage(context.Background(), &ecr.BatchGetImageInput{
 			RepositoryName: repo.RepositoryName,
+			RegistryId:     repo.RegistryId,
+			ImageIds:       []ecrTypes.ImageIdentifier{{ImageTag: &tag}},
+			AcceptedMediaTypes: []string{
+				"application/vnd.oci.image.index.v1+json",
+				"application/vnd.docker.distribution.manifest.list.v2+json",
+				"application/vnd.oci.image.manifest.v1+json",
+				"application/vnd.docker.distribution.manifest.v2+json",
+			},
 		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"profile":    profile,
+				"repository": repositoryName,
+				"tag":        tag,
+				"error":      err,
+			}).Debug("cannot get image")
 
-		images := []ecrTypes.ImageDetail{}
-		for page := 0; paginator.HasMorePages(); page++ {
-			page, err := paginator.NextPage(context.Background())
-			if err != nil {
-				log.WithFields(log.Fields{
-					"error": err,
-					"page":  page,
-				}).Error("Failed to describe images")
-			}
-
-			images = append(images, page.ImageDetails...)
+			continue
 		}
 
-		var semverImages []*semver.Version
-
-		for _, image := range images {
-			for _, tag := range image.ImageTags {
-				log.WithFields(log.Fields{
-					"image": tag,
-				}).Trace("Image")
-				version, err := semver.StrictNewVersion(tag)
-				if err != nil {
-					continue
-				}
-
-				semverImages = append(semverImages, version)
-				log.WithFields(log.Fields{
-					"image":  tag,
-					"semver": version,
-				}).Debug("found image tag")
+		for _, image := range output.Images {
+			if image.ImageId != nil && image.ImageId.ImageDigest != nil {
+				return *image.ImageId.ImageDigest, nil
 			}
 		}
+	}
+
+	return "", fmt.Errorf("cannot find the digest of %s:%s", repositoryName, tag)
+}
+
+func ecrRepo(client *ecr.Client, repositoryName string) ([]string, error) {
+	repo, err := findRepository(client, repositoryName)
+	if err != nil || repo == nil {
+		return []string{}, err
+	}
+
+	log.WithFields(log.Fields{
+		"repo": *repo.RepositoryUri,
+	}).Debug("Repository")
+
+	// describe image tags with pages
+	paginator := ecr.NewDescribeImagesPaginator(client, &ecr.DescribeImagesInput{
+		RepositoryName: repo.RepositoryName,
+	})
+
+	images := []ecrTypes.ImageDetail{}
+	for page := 0; paginator.HasMorePages(); page++ {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+				"page":  page,
+			}).Error("Failed to describe images")
+		}
+
+		images = append(images, page.ImageDetails...)
+	}
 
-		return semverImages, nil
+	var tags []string
+
+	for _, image := range images {
+		for _, tag := range image.ImageTags {
+			log.WithFields(log.Fields{
+				"image": tag,
+			}).Trace("found image tag")
+
+			tags = append(tags, tag)
+		}
 	}
 
-	return []*semver.Version{}, nil
+	return tags, nil
 }
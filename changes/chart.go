@@ -0,0 +1,117 @@
+package changes
+
+import (
+	"os"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/helm"
+	"github.com/mhristof/bump/manifest"
+	"github.com/mhristof/bump/policy"
+	log "github.com/sirupsen/logrus"
+)
+
+// chartSource updates the Helm charts of YAML files.
+type chartSource struct {
+	client *helm.Client
+}
+
+func (s chartSource) Handles(path string) bool {
+	return manifest.IsManifest(path)
+}
+
+func (s chartSource) Parse(path string, p policy.Policy) (Changes, coverage) {
+	changes, lines := parseCharts(path, s.client, p)
+
+	return changes, coverLines(lines)
+}
+
+// parseCharts returns a change for every Helm chart of the YAML file at path
+// that has a newer version the policy allows, and the lines the chart
+// versions are written in.
+func parseCharts(path string, client *helm.Client, p policy.Policy) (Changes, map[int]struct{}) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.WithField("file", path).Error("Failed to read file")
+
+		return nil, nil
+	}
+
+	charts, err := manifest.ParseCharts(data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  path,
+			"error": err,
+		}).Debug("cannot parse yaml")
+
+		return nil, nil
+	}
+
+	var ret Changes
+
+	lines := map[int]struct{}{}
+
+	for _, chart := range charts {
+		lines[chart.Line] = struct{}{}
+
+		change := chartChange(path, chart, client, p)
+		if change == nil {
+			continue
+		}
+
+		ret = append(ret, change)
+	}
+
+	return ret, lines
+}
+
+func chartChange(path string, chart manifest.Chart, client *helm.Client, p policy.Policy) *Change {
+	current, err := semver.NewVersion(chart.Version)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"chart":   chart.Name,
+			"version": chart.Version,
+		}).Debug("cannot parse chart version")
+
+		return nil
+	}
+
+	available, err := client.Versions(chart.Repository, chart.Name)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"chart":      chart.Name,
+			"repository": chart.Repository,
+			"error":      err,
+		}).Debug("cannot retrieve chart versions")
+
+		return nil
+	}
+
+	var versions []*semver.Version
+
+	for _, v := range available {
+		version, err := semver.NewVersion(v)
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	next := p.Select(path, "helm", current, versions)
+	if next == nil || next.Original() == chart.Version {
+		return nil
+	}
+
+	edit := chart.Rewrite(next.Original())
+
+	return &Change{
+		line:       edit.Text,
+		lineNumber: edit.Line,
+		NewLine:    edit.NewText,
+		Module:     chart.Name,
+		file:       path,
+		version:    current,
+		newVersion: next,
+		updater:    "helm",
+	}
+}
@@ -0,0 +1,125 @@
+package changes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/bump/helm"
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/updater/oci"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCharts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		fmt.Fprint(w, "entries:\n  postgresql:\n    - version: 12.5.6\n    - version: 12.6.0\n    - version: 13.0.0\n")
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "Chart.yaml")
+
+	data := `apiVersion: v2
+name: app
+version: 0.1.0
+dependencies:
+  - name: postgresql
+    version: 12.5.6 # database
+    repository: ` + srv.URL + `
+  - name: common
+    version: 1.0.0
+    repository: file://../common
+`
+
+	err := os.WriteFile(path, []byte(data), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := helm.NewClient(oci.NewClient(""))
+
+	changes, lines := parseCharts(path, client, policy.Policy{Level: policy.Minor})
+	if !assert.Len(t, changes, 1) {
+		return
+	}
+
+	assert.Equal(t, map[int]struct{}{6: {}}, lines)
+	assert.Equal(t, "helm", changes[0].Report().Type)
+
+	changes[0].Apply()
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(updated), "    version: 12.6.0 # database\n")
+
+	changes, _ = parseCharts(path, client, policy.Policy{Level: policy.Minor})
+	assert.Len(t, changes, 0)
+}
+
+func TestParseChartsSharedVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		fmt.Fprint(w, "entries:\n  a:\n    - version: 1.2.3\n    - version: 1.3.0\n  b:\n    - version: 1.2.3\n")
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "Chart.yaml")
+
+	data := `apiVersion: v2
+name: app
+version: 0.1.0
+dependencies:
+  - name: a
+    version: 1.2.3
+    repository: ` + srv.URL + `
+  - name: b
+    version: 1.2.3
+    repository: ` + srv.URL + `
+`
+
+	err := os.WriteFile(path, []byte(data), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, _ := parseCharts(path, helm.NewClient(oci.NewClient("")), policy.Policy{})
+	if !assert.Len(t, changes, 1) {
+		return
+	}
+
+	changes[0].Apply()
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `apiVersion: v2
+name: app
+version: 0.1.0
+dependencies:
+  - name: a
+    version: 1.3.0
+    repository: `+srv.URL+`
+  - name: b
+    version: 1.2.3
+    repository: `+srv.URL+`
+`, string(updated))
+}
@@ -0,0 +1,124 @@
+package changes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	colorReset = "\033[0m"
+	colorBold  = "\033[1m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+)
+
+// Diff writes a unified diff for every file the changes would modify,
+// without touching the files. Changes without a file are skipped.
+func (c Changes) Diff(w io.Writer, color bool) error {
+	var files []string
+
+	byFile := map[string][]*Change{}
+
+	for _, change := range c {
+		if change.file == "" {
+			continue
+		}
+
+		if _, ok := byFile[change.file]; !ok {
+			files = append(files, change.file)
+		}
+
+		byFile[change.file] = append(byFile[change.file], change)
+	}
+
+	for _, file := range files {
+		before, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("cannot read %s: %w", file, err)
+		}
+
+		after := before
+
+		for _, change := range byFile[file] {
+			data, err := change.Render(after)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"change": change,
+					"error":  err,
+				}).Error("cannot render change")
+
+				continue
+			}
+
+			after = data
+		}
+
+		fromFile, toFile := file, file
+		if !filepath.IsAbs(file) {
+			fromFile, toFile = "a/"+file, "b/"+file
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(string(before)),
+			B:        splitLines(string(after)),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot diff %s: %w", file, err)
+		}
+
+		if color {
+			diff = colorize(diff)
+		}
+
+		_, err = io.WriteString(w, diff)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitLines splits data after every newline. Unlike difflib.SplitLines it
+// does not add an empty line when data ends with a newline.
+func splitLines(data string) []string {
+	lines := strings.SplitAfter(data, "\n")
+
+	last := len(lines) - 1
+	if lines[last] == "" {
+		return lines[:last]
+	}
+
+	lines[last] += "\n"
+
+	return lines
+}
+
+func colorize(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			lines[i] = colorBold + strings.TrimSuffix(line, "\n") + colorReset + "\n"
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = colorCyan + strings.TrimSuffix(line, "\n") + colorReset + "\n"
+		case strings.HasPrefix(line, "-"):
+			lines[i] = colorRed + strings.TrimSuffix(line, "\n") + colorReset + "\n"
+		case strings.HasPrefix(line, "+"):
+			lines[i] = colorGreen + strings.TrimSuffix(line, "\n") + colorReset + "\n"
+		}
+	}
+
+	return strings.Join(lines, "")
+}
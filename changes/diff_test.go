@@ -0,0 +1,80 @@
+package changes
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	content := heredoc.Doc(`
+		module "vpc" {
+		  source  = "terraform-aws-modules/vpc/aws"
+		  version = "~> 4.0"
+		}
+
+		locals {
+		  image = "prom/alertmanager:v0.25.0"
+		}
+	`)
+
+	file := generateFile(t, content)
+	defer os.Remove(file)
+
+	changes := Changes{
+		{
+			file:    file,
+			format:  Terraform,
+			Module:  "vpc",
+			line:    "~> 4.0",
+			NewLine: "~> 5.0",
+		},
+		{
+			file:    file,
+			format:  String,
+			line:    `  image = "prom/alertmanager:v0.25.0"`,
+			NewLine: `  image = "prom/alertmanager:v0.26.0"`,
+		},
+		{
+			line:    "prom/alertmanager:v0.25.0",
+			NewLine: "prom/alertmanager:v0.26.0",
+		},
+	}
+
+	want := strings.ReplaceAll(heredoc.Doc(`
+		--- FILE
+		+++ FILE
+		@@ -1,8 +1,8 @@
+		 module "vpc" {
+		   source  = "terraform-aws-modules/vpc/aws"
+		-  version = "~> 4.0"
+		+  version = "~> 5.0"
+		 }
+		 
+		 locals {
+		-  image = "prom/alertmanager:v0.25.0"
+		+  image = "prom/alertmanager:v0.26.0"
+		 }
+	`), "FILE", file)
+
+	var out bytes.Buffer
+
+	err := changes.Diff(&out, false)
+	assert.Nil(t, err)
+	assert.Equal(t, want, out.String())
+
+	data, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, content, string(data), "diff must not modify the file")
+
+	out.Reset()
+
+	err = changes.Diff(&out, true)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), colorRed+`-  version = "~> 4.0"`+colorReset)
+	assert.Contains(t, out.String(), colorGreen+`+  version = "~> 5.0"`+colorReset)
+}
@@ -0,0 +1,159 @@
+package changes
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mhristof/bump/dockerfile"
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+// dockerfileSource updates the images of Dockerfiles, which it handles
+// whole.
+type dockerfileSource struct {
+	updaters updater.Set
+}
+
+func (s dockerfileSource) Handles(path string) bool {
+	return dockerfile.IsDockerfile(path)
+}
+
+func (s dockerfileSource) Parse(path string, p policy.Policy) (Changes, coverage) {
+	return parseDockerfile(path, s.updaters, p), coverAll()
+}
+
+// parseDockerfile returns a change for every image of the Dockerfile at path
+// that the registry updater handling it has a newer tag for. Tags that come
+// from an ARG are updated in the ARG. Images pinned to a digest, or every
+// image with the pin policy, get the digest of the new tag in the FROM line.
+func parseDockerfile(path string, updaters updater.Set, p policy.Policy) Changes {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.WithField("file", path).Error("Failed to read file")
+
+		return nil
+	}
+
+	var ret Changes
+
+	seen := map[string]struct{}{}
+
+	for _, image := range dockerfile.Parse(data) {
+		reference := image.Reference()
+
+		if image.Tag == "" {
+			log.WithField("image", reference).Debug("skipping image without a tag")
+
+			continue
+		}
+
+		u := updaters.Match(path, reference)
+		if u == nil {
+			log.WithField("image", reference).Debug("no updater for image")
+
+			continue
+		}
+
+		line := reference
+		if image.Digest != "" {
+			line += "@" + image.Digest
+		}
+
+		change := &Change{
+			line: line,
+			file: path,
+		}
+
+		if !change.update(u, p) {
+			continue
+		}
+
+		for _, c := range imageChanges(image, change) {
+			key := strconv.Itoa(c.lineNumber) + "\x00" + c.NewLine
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			ret = append(ret, c)
+		}
+	}
+
+	return ret
+}
+
+// imageChanges turns the change of the reference of image into changes of
+// the lines of the Dockerfile. The tag and the digest are written in
+// different lines when the tag comes from an ARG.
+func imageChanges(image dockerfile.Image, change *Change) Changes {
+	reference, digest, _ := strings.Cut(change.NewLine, "@")
+	tag := reference[strings.LastIndex(reference, ":")+1:]
+
+	var ret Changes
+
+	text, newText := image.Text(), image.Text()
+
+	if tag != image.Tag {
+		var err error
+
+		newText, err = image.Rewrite(tag)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"file":  change.file,
+				"image": image.Reference(),
+				"error": err,
+			}).Warning("cannot rewrite image")
+
+			return nil
+		}
+	}
+
+	if digest != "" && digest != image.Digest {
+		if image.Line != image.From {
+			fromText, err := dockerfile.SetDigest(image.FromText(), digest)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"file":  change.file,
+					"image": image.Reference(),
+					"error": err,
+				}).Warning("cannot pin image")
+
+				return nil
+			}
+
+			pinned := *change
+			pinned.line = image.FromText()
+			pinned.NewLine = fromText
+			pinned.lineNumber = image.From
+
+			ret = append(ret, &pinned)
+		} else {
+			var err error
+
+			newText, err = dockerfile.SetDigest(newText, digest)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"file":  change.file,
+					"image": image.Reference(),
+					"error": err,
+				}).Warning("cannot pin image")
+
+				return nil
+			}
+		}
+	}
+
+	if newText != text {
+		change.line = text
+		change.NewLine = newText
+		change.lineNumber = image.Line
+
+		ret = append(Changes{change}, ret...)
+	}
+
+	return ret
+}
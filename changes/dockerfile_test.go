@@ -0,0 +1,273 @@
+package changes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/updater"
+	"github.com/stretchr/testify/assert"
+)
+
+// registryUpdater handles the images that contain prefix and offers the
+// versions of the image repository.
+type registryUpdater struct {
+	prefix   string
+	versions map[string][]string
+}
+
+func (r registryUpdater) Match(file, line string) bool {
+	return strings.Contains(line, r.prefix)
+}
+
+func (r registryUpdater) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	line, _, _ = strings.Cut(line, "@")
+
+	current, err := semver.NewVersion(line[strings.LastIndex(line, ":")+1:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ret []*semver.Version
+	for _, v := range r.versions[line[:strings.LastIndex(line, ":")]] {
+		ret = append(ret, semver.MustParse(v))
+	}
+
+	return current, ret, nil
+}
+
+func (r registryUpdater) Rewrite(line string, current, next *semver.Version) string {
+	return strings.ReplaceAll(line, current.Original(), next.Original())
+}
+
+// pinningUpdater is a registryUpdater that resolves name:tag to digests.
+type pinningUpdater struct {
+	registryUpdater
+	digests map[string]string
+}
+
+func (p pinningUpdater) Pin(line string) (string, error) {
+	image, _, _ := strings.Cut(line, "@")
+
+	digest, ok := p.digests[image]
+	if !ok {
+		return "", fmt.Errorf("no digest for %s", image)
+	}
+
+	return updater.SetDigest(line, image, digest), nil
+}
+
+func TestParseDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Dockerfile")
+
+	data := `ARG GO_VERSION=1.20.5
+FROM golang:${GO_VERSION} AS build
+FROM ghcr.io/org/app:v1.2.3
+FROM alpine:3.18
+COPY --from=build /app /app
+`
+
+	err := os.WriteFile(path, []byte(data), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updaters := updater.Set{
+		{Name: "ghcr", Updater: registryUpdater{prefix: "ghcr.io/", versions: map[string][]string{
+			"ghcr.io/org/app": {"v1.3.0"},
+		}}},
+		{Name: "dockerhub", Updater: registryUpdater{prefix: "library/", versions: map[string][]string{
+			"library/golang": {"1.21.0"},
+			"library/alpine": {"3.19"},
+		}}},
+	}
+
+	changes := parseDockerfile(path, updaters, policy.Policy{})
+
+	type want struct {
+		updater string
+		line    int
+		newLine string
+	}
+
+	got := make([]want, len(changes))
+	for i, change := range changes {
+		got[i] = want{change.updater, change.lineNumber, change.NewLine}
+	}
+
+	assert.Equal(t, []want{
+		{updater: "dockerhub", line: 1, newLine: "ARG GO_VERSION=1.21.0"},
+		{updater: "ghcr", line: 3, newLine: "FROM ghcr.io/org/app:v1.3.0"},
+		{updater: "dockerhub", line: 4, newLine: "FROM alpine:3.19"},
+	}, got)
+
+	for _, change := range changes {
+		change.Apply()
+	}
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `ARG GO_VERSION=1.21.0
+FROM golang:${GO_VERSION} AS build
+FROM ghcr.io/org/app:v1.3.0
+FROM alpine:3.19
+COPY --from=build /app /app
+`, string(updated))
+}
+
+func TestParseDockerfileDigests(t *testing.T) {
+	digest := func(c string) string { return "sha256:" + strings.Repeat(c, 64) }
+
+	data := `ARG GO_VERSION=1.20.5
+FROM golang:${GO_VERSION}@` + digest("0") + ` AS build
+FROM alpine:3.19
+FROM ghcr.io/org/app:v1.2.3@` + digest("1") + `
+`
+
+	updaters := updater.Set{
+		{Name: "ghcr", Updater: pinningUpdater{
+			registryUpdater: registryUpdater{prefix: "ghcr.io/", versions: map[string][]string{
+				"ghcr.io/org/app": {"v1.3.0"},
+			}},
+			digests: map[string]string{"ghcr.io/org/app:v1.3.0": digest("a")},
+		}},
+		{Name: "dockerhub", Updater: pinningUpdater{
+			registryUpdater: registryUpdater{prefix: "library/", versions: map[string][]string{
+				"library/golang": {"1.21.0"},
+				"library/alpine": {"3.19"},
+			}},
+			digests: map[string]string{
+				"library/golang:1.21.0": digest("b"),
+				"library/alpine:3.19":   digest("c"),
+			},
+		}},
+	}
+
+	cases := []struct {
+		name string
+		pin  bool
+		want string
+	}{
+		{
+			name: "pinned images are updated with their digest",
+			want: `ARG GO_VERSION=1.21.0
+FROM golang:${GO_VERSION}@` + digest("b") + ` AS build
+FROM alpine:3.19
+FROM ghcr.io/org/app:v1.3.0@` + digest("a") + `
+`,
+		},
+		{
+			name: "pin policy pins every image",
+			pin:  true,
+			want: `ARG GO_VERSION=1.21.0
+FROM golang:${GO_VERSION}@` + digest("b") + ` AS build
+FROM alpine:3.19@` + digest("c") + `
+FROM ghcr.io/org/app:v1.3.0@` + digest("a") + `
+`,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Dockerfile")
+
+			err := os.WriteFile(path, []byte(data), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, change := range parseDockerfile(path, updaters, policy.Policy{Pin: test.pin}) {
+				change.Apply()
+			}
+
+			updated, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.want, string(updated), test.name)
+		})
+	}
+}
+
+func TestParseDockerfileDigestDrift(t *testing.T) {
+	digest := func(c string) string { return "sha256:" + strings.Repeat(c, 64) }
+
+	path := filepath.Join(t.TempDir(), "Dockerfile")
+
+	data := `FROM node:20@` + digest("0") + `
+FROM ubuntu:22.04@` + digest("b") + `
+`
+
+	err := os.WriteFile(path, []byte(data), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updaters := updater.Set{
+		{Name: "dockerhub", Updater: pinningUpdater{
+			registryUpdater: registryUpdater{prefix: "library/", versions: map[string][]string{
+				"library/node":   {"20"},
+				"library/ubuntu": {"22.04"},
+			}},
+			digests: map[string]string{
+				"library/node:20":      digest("a"),
+				"library/ubuntu:22.04": digest("b"),
+			},
+		}},
+	}
+
+	changes := parseDockerfile(path, updaters, policy.Policy{})
+	if !assert.Len(t, changes, 1) {
+		return
+	}
+
+	assert.Equal(t, Digest, changes[0].kind)
+	assert.Equal(t, "FROM node:20@"+digest("a"), changes[0].NewLine)
+
+	report := changes[0].Report()
+	assert.Equal(t, "digest", report.Kind)
+	assert.Equal(t, digest("0"), report.Current)
+	assert.Equal(t, digest("a"), report.Proposed)
+}
+
+func TestParseDockerfileFirstPin(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("c", 64)
+
+	path := filepath.Join(t.TempDir(), "Dockerfile")
+
+	err := os.WriteFile(path, []byte("FROM alpine:3.19\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updaters := updater.Set{
+		{Name: "dockerhub", Updater: pinningUpdater{
+			registryUpdater: registryUpdater{prefix: "library/", versions: map[string][]string{
+				"library/alpine": {"3.19"},
+			}},
+			digests: map[string]string{"library/alpine:3.19": digest},
+		}},
+	}
+
+	changes := parseDockerfile(path, updaters, policy.Policy{Pin: true})
+	if !assert.Len(t, changes, 1) {
+		return
+	}
+
+	assert.Equal(t, Pin, changes[0].kind)
+	assert.Equal(t, "FROM alpine:3.19@"+digest, changes[0].NewLine)
+
+	report := changes[0].Report()
+	assert.Equal(t, "pin", report.Kind)
+	assert.Equal(t, "3.19", report.Current)
+	assert.Equal(t, "3.19", report.Proposed)
+}
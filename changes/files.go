@@ -0,0 +1,160 @@
+package changes
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	ignore "github.com/sabhiram/go-gitignore"
+	log "github.com/sirupsen/logrus"
+)
+
+// skipDirs are never scanned.
+var skipDirs = map[string]struct{}{
+	".git":       {},
+	".terraform": {},
+	"vendor":     {},
+}
+
+// Filter selects the files scanned inside a directory. Patterns are
+// doublestar globs matched against the path relative to the directory, or
+// against the file name when they do not contain a slash.
+type Filter struct {
+	Include []string
+	Exclude []string
+}
+
+type gitignore struct {
+	dir     string
+	matcher *ignore.GitIgnore
+}
+
+// Files returns the files under root that pass the filter, skipping the
+// paths ignored by any .gitignore found on the way.
+func (f Filter) Files(root string) ([]string, error) {
+	var ret []string
+
+	var ignores []gitignore
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel == "." {
+				ignores = loadGitignore(ignores, p)
+
+				return nil
+			}
+
+			if _, ok := skipDirs[d.Name()]; ok || ignored(ignores, p, true) || matchAny(f.Exclude, rel) {
+				log.WithField("dir", p).Trace("skipping directory")
+
+				return filepath.SkipDir
+			}
+
+			ignores = loadGitignore(ignores, p)
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() || ignored(ignores, p, false) || matchAny(f.Exclude, rel) {
+			return nil
+		}
+
+		if len(f.Include) > 0 && !matchAny(f.Include, rel) {
+			return nil
+		}
+
+		ret = append(ret, p)
+
+		return nil
+	})
+
+	return ret, err
+}
+
+func loadGitignore(ignores []gitignore, dir string) []gitignore {
+	matcher, err := ignore.CompileIgnoreFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.WithFields(log.Fields{
+				"dir":   dir,
+				"error": err,
+			}).Warning("cannot read .gitignore")
+		}
+
+		return ignores
+	}
+
+	return append(ignores, gitignore{dir: dir, matcher: matcher})
+}
+
+// ignored reports whether p is ignored by a .gitignore of one of its parent
+// directories.
+func ignored(ignores []gitignore, p string, dir bool) bool {
+	for _, i := range ignores {
+		rel, err := filepath.Rel(i.dir, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		rel = filepath.ToSlash(rel)
+		if dir {
+			rel += "/"
+		}
+
+		if i.matcher.MatchesPath(rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+
+		ok, err := doublestar.Match(pattern, name)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"pattern": pattern,
+				"error":   err,
+			}).Warning("invalid pattern")
+
+			continue
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBinary reports whether data looks like the contents of a binary file.
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,129 @@
+package changes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tree(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+
+	for name, content := range files {
+		p := filepath.Join(root, name)
+
+		err := os.MkdirAll(filepath.Dir(p), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(p, []byte(content), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestFilterFiles(t *testing.T) {
+	root := tree(t, map[string]string{
+		".gitignore":                        "build/\n*.log\n",
+		"main.tf":                           "",
+		"with space.tf":                     "",
+		"Dockerfile":                        "",
+		"debug.log":                         "",
+		"build/out.tf":                      "",
+		"vendor/github.com/foo/main.go":     "",
+		".terraform/modules/vpc/main.tf":    "",
+		".git/config":                       "",
+		"modules/vpc/main.tf":               "",
+		"modules/vpc/.gitignore":            "generated.tf\n",
+		"modules/vpc/generated.tf":          "",
+		"modules/vpc/examples/simple/x.tf":  "",
+		"charts/app/values.yaml":            "",
+		"charts/app/templates/service.yaml": "",
+	})
+
+	cases := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{
+			name: "everything that is not ignored",
+			want: []string{
+				".gitignore",
+				"Dockerfile",
+				"charts/app/templates/service.yaml",
+				"charts/app/values.yaml",
+				"main.tf",
+				"modules/vpc/.gitignore",
+				"modules/vpc/examples/simple/x.tf",
+				"modules/vpc/main.tf",
+				"with space.tf",
+			},
+		},
+		{
+			name:   "include by file name",
+			filter: Filter{Include: []string{"*.tf"}},
+			want: []string{
+				"main.tf",
+				"modules/vpc/examples/simple/x.tf",
+				"modules/vpc/main.tf",
+				"with space.tf",
+			},
+		},
+		{
+			name: "include and exclude by path",
+			filter: Filter{
+				Include: []string{"*.tf", "charts/**/values.yaml"},
+				Exclude: []string{"modules/*/examples"},
+			},
+			want: []string{
+				"charts/app/values.yaml",
+				"main.tf",
+				"modules/vpc/main.tf",
+				"with space.tf",
+			},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			files, err := test.filter.Files(root)
+			assert.Nil(t, err, test.name)
+
+			var got []string
+			for _, file := range files {
+				rel, err := filepath.Rel(root, file)
+				assert.Nil(t, err, test.name)
+
+				got = append(got, filepath.ToSlash(rel))
+			}
+
+			assert.Equal(t, test.want, got, test.name)
+		})
+	}
+}
+
+func TestNewDirectory(t *testing.T) {
+	root := tree(t, map[string]string{
+		"a b/Dockerfile": "FROM prom/alertmanager:v0.25.0\n",
+		"binary":         "\x00\x01 v1.2.3",
+		"vendor/x.txt":   "v1.2.3",
+	})
+
+	changes := New([]string{root}, Filter{})
+
+	var lines []string
+	for _, change := range changes {
+		if change.line != "" {
+			lines = append(lines, change.line)
+		}
+	}
+
+	assert.Equal(t, []string{"FROM prom/alertmanager:v0.25.0"}, lines)
+}
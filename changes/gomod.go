@@ -0,0 +1,119 @@
+package changes
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/gomod"
+	"github.com/mhristof/bump/policy"
+	log "github.com/sirupsen/logrus"
+)
+
+// goModSource updates the requirements of go.mod files, which it handles
+// whole.
+type goModSource struct {
+	proxy *gomod.Proxy
+}
+
+func (s goModSource) Handles(path string) bool {
+	return gomod.IsGoMod(path)
+}
+
+func (s goModSource) Parse(path string, p policy.Policy) (Changes, coverage) {
+	return parseGoMod(path, s.proxy, p), coverAll()
+}
+
+// parseGoMod returns a change for every direct requirement of the go.mod at
+// path that has a newer version the policy allows, looked up through proxy.
+// Only go.mod is rewritten: go.sum is not updated, so go mod tidy has to run
+// after the changes are applied.
+func parseGoMod(path string, proxy *gomod.Proxy, p policy.Policy) Changes {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.WithField("file", path).Error("Failed to read file")
+
+		return nil
+	}
+
+	requires, err := gomod.Parse(data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  path,
+			"error": err,
+		}).Error("cannot parse go.mod")
+
+		return nil
+	}
+
+	var ret Changes
+
+	for _, require := range requires {
+		change := goModChange(path, require, proxy, p)
+		if change == nil {
+			continue
+		}
+
+		ret = append(ret, change)
+	}
+
+	return ret
+}
+
+func goModChange(path string, require gomod.Require, proxy *gomod.Proxy, p policy.Policy) *Change {
+	current, err := semver.NewVersion(require.Version)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"module":  require.Path,
+			"version": require.Version,
+		}).Debug("cannot parse module version")
+
+		return nil
+	}
+
+	available, err := proxy.Versions(require.Path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"module": require.Path,
+			"error":  err,
+		}).Debug("cannot retrieve module versions")
+
+		return nil
+	}
+
+	var versions []*semver.Version
+
+	for _, v := range gomod.Candidates(require.Path, require.Version, available) {
+		version, err := semver.NewVersion(v)
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	next := p.Select(path, GoMod.String(), current, versions)
+	if next == nil || next.Original() == require.Version {
+		return nil
+	}
+
+	change := &Change{
+		line:       require.Version,
+		lineNumber: require.Line,
+		NewLine:    next.Original(),
+		Module:     require.Path,
+		file:       path,
+		version:    current,
+		newVersion: next,
+		format:     GoMod,
+	}
+
+	if strings.HasPrefix(require.Path, "github.com/") {
+		fields := strings.Split(require.Path, "/")
+		if len(fields) >= 3 {
+			change.Source = "https://" + strings.Join(fields[:3], "/")
+		}
+	}
+
+	return change
+}
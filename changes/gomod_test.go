@@ -0,0 +1,81 @@
+package changes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/bump/gomod"
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGoMod(t *testing.T) {
+	proxyDir := t.TempDir()
+
+	for name, data := range map[string]string{
+		"github.com/pkg/errors/@v/list":           "v0.8.1\nv0.9.1\nv0.10.0\n",
+		"github.com/google/go-github/v50/@v/list": "v50.1.0\nv50.2.0\n",
+		"github.com/docker/docker/@v/list":        "v20.10.24+incompatible\nv24.0.5+incompatible\nv25.0.0-beta.1+incompatible\n",
+	} {
+		path := filepath.Join(proxyDir, filepath.FromSlash(name))
+
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(data), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "go.mod")
+
+	err := os.WriteFile(path, []byte(heredoc.Doc(`
+		module example.com/app
+
+		go 1.19
+
+		require (
+			github.com/docker/docker v20.10.24+incompatible
+			github.com/google/go-github/v50 v50.2.0
+			github.com/pkg/errors v0.9.1
+		)
+	`)), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxy := gomod.NewProxy("file://"+filepath.ToSlash(proxyDir), "")
+
+	changes := parseGoMod(path, proxy, policy.Policy{Level: policy.Major})
+
+	for _, change := range changes {
+		change.Apply()
+	}
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, heredoc.Doc(`
+		module example.com/app
+
+		go 1.19
+
+		require (
+			github.com/docker/docker v24.0.5+incompatible
+			github.com/google/go-github/v50 v50.2.0
+			github.com/pkg/errors v0.10.0
+		)
+	`), string(updated))
+
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, "https://github.com/docker/docker", changes[0].Source)
+		assert.Equal(t, GoMod, changes[0].format)
+	}
+}
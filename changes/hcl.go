@@ -1,16 +1,21 @@
 package changes
 
 import (
-	"bytes"
-	"encoding/json"
+	"fmt"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/mhristof/bump/policy"
 	"github.com/mhristof/bump/terraform"
+	"github.com/mhristof/bump/updater/git"
 	log "github.com/sirupsen/logrus"
-	"github.com/tmccombs/hcl2json/convert"
+	"github.com/zclconf/go-cty/cty"
 )
 
 type Config struct {
@@ -23,84 +28,480 @@ type Module struct {
 	Version string `hcl:"version,optional"`
 }
 
-type Foo struct {
-	Terraform []struct {
-		RequiredProviders []struct {
-			Name    string `hcl:"name,label"`
-			Version string `hcl:"version,optional"`
-		} `hcl:"required_providers,block"`
+// Provider is an entry of a terraform required_providers block.
+type Provider struct {
+	Name    string
+	Source  string
+	Version string
+	Line    int
+}
+
+// requiredProviders returns the providers of every required_providers block
+// in data, sorted by name. Entries in the legacy string form get the implied
+// hashicorp/<name> source.
+func requiredProviders(path string, data []byte) []Provider {
+	file, diags := hclsyntax.ParseConfig(data, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		log.WithFields(log.Fields{
+			"file":  path,
+			"error": diags,
+		}).Error("cannot parse HCL")
+
+		return nil
+	}
+
+	var ret []Provider
+
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "terraform" {
+			continue
+		}
+
+		for _, inner := range block.Body.Blocks {
+			if inner.Type != "required_providers" {
+				continue
+			}
+
+			for name, attr := range inner.Body.Attributes {
+				value, diags := attr.Expr.Value(nil)
+				if diags.HasErrors() || value.IsNull() || !value.IsKnown() {
+					continue
+				}
+
+				provider := Provider{
+					Name:   name,
+					Source: "hashicorp/" + name,
+					Line:   versionLine(attr.Expr),
+				}
+
+				switch {
+				case value.Type() == cty.String:
+					provider.Version = value.AsString()
+				case value.Type().IsObjectType():
+					if source := stringAttr(value, "source"); source != "" {
+						provider.Source = source
+					}
+
+					provider.Version = stringAttr(value, "version")
+				default:
+					continue
+				}
+
+				ret = append(ret, provider)
+			}
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+
+	return ret
+}
+
+// hclSource updates the modules and required providers of Terraform files.
+type hclSource struct{}
+
+func (hclSource) Handles(path string) bool {
+	return strings.HasSuffix(path, ".tf")
+}
+
+func (hclSource) Parse(path string, p policy.Policy) (Changes, coverage) {
+	changes, lines := parseHCL(path, p)
 
-		RequiredVersion string `hcl:"required_version"`
-	} `hcl:"terraform,block"`
+	return changes, coverLines(lines)
 }
 
-func parseHCL(path string) Changes {
+// parseHCL returns a change for every module and required provider of the
+// Terraform file at path that has a newer version the policy allows, and the
+// lines the versions and git sources are written in.
+func parseHCL(path string, p policy.Policy) (Changes, map[int]struct{}) {
 	log.WithField("file", path).Debug("Parsing HCL")
 
 	var config Config
 
-	// load file
 	data, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatalf("Failed to read configuration: %s", err)
+		log.WithField("file", path).Error("Failed to read file")
+
+		return nil, nil
+	}
+
+	_, diags := hclsyntax.ParseConfig(data, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		log.WithFields(log.Fields{
+			"file":  path,
+			"error": diags,
+		}).Error("cannot parse HCL")
+
+		return nil, nil
 	}
 
 	_ = hclsimple.Decode("foo.hcl", data, nil, &config)
 
 	var ret Changes
 
+	covered := map[int]struct{}{}
+	lines := moduleLines(path, data, "version")
+	sourceLines := moduleLines(path, data, "source")
+
 	for _, module := range config.Modules {
 		log.WithField("module", module).Debug("Module")
 
-		versions, source := terraform.RegistryVersions(module.Source)
+		if source, ok := terraform.ParseGitSource(module.Source); ok {
+			covered[sourceLines[module.Name]] = struct{}{}
 
-		sort.Sort(sort.Reverse(semver.Collection(versions)))
+			change := gitModuleChange(path, module, source, p)
+			if change == nil {
+				continue
+			}
 
-		moduleVersion, err := semver.NewVersion(module.Version)
+			change.lineNumber = sourceLines[module.Name]
+
+			ret = append(ret, change)
+
+			continue
+		}
+
+		constraint, err := terraform.ParseConstraint(module.Version)
 		if err != nil {
-			//log.WithFields(log.Fields{
-			//"module": module.Name,
-			//}).Warning("failed to parse version")
+			log.WithFields(log.Fields{
+				"module":  module.Name,
+				"version": module.Version,
+				"error":   err,
+			}).Debug("cannot parse module version")
+
 			continue
 		}
 
-		for i := 0; i < len(versions); i++ {
-			if versions[i].GreaterThan(moduleVersion) {
-				log.WithFields(log.Fields{
-					"module":  module.Name,
-					"version": versions[i],
-				}).Debug("found latest change")
-
-				ret = append(ret, &Change{
-					line:       string(data),
-					Module:     module.Name,
-					file:       path,
-					format:     Terraform,
-					version:    moduleVersion,
-					newVersion: versions[i],
-					Source:     source,
-				})
-
-				break
-			}
+		covered[lines[module.Name]] = struct{}{}
+
+		versions, source, err := terraform.RegistryVersions(module.Source)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"module": module.Name,
+				"error":  err,
+			}).Warning("cannot retrieve module versions")
+
+			continue
+		}
+
+		change := constraintChange(constraint, versions, p.For(path, Terraform.String()), p.Prerelease)
+		if change == nil {
+			continue
 		}
+
+		change.Module = module.Name
+		change.file = path
+		change.lineNumber = lines[module.Name]
+		change.format = Terraform
+		change.Source = source
+
+		ret = append(ret, change)
 	}
 
-	// var versions Foo
+	for _, provider := range requiredProviders(path, data) {
+		log.WithField("provider", provider).Debug("Provider")
 
-	var options convert.Options
-	converted, err := convert.Bytes(data, "foo", options)
-	if err != nil {
-		log.Fatalf("Failed to convert: %s", err)
+		constraint, err := terraform.ParseConstraint(provider.Version)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"provider": provider.Name,
+				"version":  provider.Version,
+				"error":    err,
+			}).Debug("cannot parse provider version")
+
+			continue
+		}
+
+		covered[provider.Line] = struct{}{}
+
+		versions, source, err := terraform.ProviderVersions(provider.Source)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"provider": provider.Name,
+				"error":    err,
+			}).Warning("cannot retrieve provider versions")
+
+			continue
+		}
+
+		change := constraintChange(constraint, versions, p.For(path, TerraformProvider.String()), p.Prerelease)
+		if change == nil {
+			continue
+		}
+
+		change.Module = provider.Name
+		change.file = path
+		change.lineNumber = provider.Line
+		change.format = TerraformProvider
+		change.Source = source
+
+		ret = append(ret, change)
 	}
 
-	// prettyConverted, _ := json.MarshalIndent(converted, "", "  ")
-	// fmt.Println(string(prettyConverted))
+	return ret, covered
+}
+
+// versionLine returns the line of the version string of a required_providers
+// entry.
+func versionLine(expr hclsyntax.Expression) int {
+	object, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return expr.Range().Start.Line
+	}
 
-	var indented bytes.Buffer
-	if err := json.Indent(&indented, converted, "", "    "); err != nil {
-		log.Fatalf("Failed to indent: %s", err)
+	for _, item := range object.Items {
+		if hcl.ExprAsKeyword(item.KeyExpr) == "version" {
+			return item.ValueExpr.Range().Start.Line
+		}
+	}
+
+	return expr.Range().Start.Line
+}
+
+// moduleLines returns the line of the attribute of every module block in
+// data.
+func moduleLines(path string, data []byte, attribute string) map[string]int {
+	ret := map[string]int{}
+
+	file, diags := hclsyntax.ParseConfig(data, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return ret
+	}
+
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "module" || len(block.Labels) != 1 {
+			continue
+		}
+
+		if attr, ok := block.Body.Attributes[attribute]; ok {
+			ret[block.Labels[0]] = attr.SrcRange.Start.Line
+		}
 	}
 
 	return ret
 }
+
+func stringAttr(value cty.Value, name string) string {
+	if !value.Type().HasAttribute(name) {
+		return ""
+	}
+
+	attr := value.GetAttr(name)
+	if attr.IsNull() || !attr.IsKnown() || attr.Type() != cty.String {
+		return ""
+	}
+
+	return attr.AsString()
+}
+
+// gitModuleChange returns a change of the ref of a module sourced from a git
+// repository when one of its tags is a newer version the policy allows.
+// Refs that are branches or commits are left alone.
+func gitModuleChange(path string, module Module, source terraform.GitSource, p policy.Policy) *Change {
+	current, err := semver.NewVersion(source.Ref)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"module": module.Name,
+			"ref":    source.Ref,
+		}).Debug("ref is not a version")
+
+		return nil
+	}
+
+	remote := source.Remote
+	if github := source.GitHub(); github != "" {
+		remote = github
+	}
+
+	tags, err := git.Tags(remote)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"module": module.Name,
+			"remote": remote,
+			"error":  err,
+		}).Warning("cannot retrieve module tags")
+
+		return nil
+	}
+
+	var versions []*semver.Version
+
+	for _, tag := range tags {
+		version, err := semver.NewVersion(tag.Name)
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	next := p.Select(path, TerraformSource.String(), current, versions)
+	if next == nil || next.Original() == source.Ref {
+		return nil
+	}
+
+	return &Change{
+		line:       module.Source,
+		NewLine:    terraform.SetRef(module.Source, next.Original()),
+		Module:     module.Name,
+		file:       path,
+		version:    current,
+		newVersion: next,
+		format:     TerraformSource,
+		Source:     source.GitHub(),
+	}
+}
+
+// constraintChange returns a change when the newest of versions the level
+// and prerelease allow falls outside constraint. The change goes from the
+// newest version the constraint allows to that version, and carries the
+// rewritten constraint.
+func constraintChange(constraint *terraform.Constraint, versions []*semver.Version, level policy.Level, prerelease policy.Prerelease) *Change {
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+
+	current := constraint.Version()
+
+	for _, version := range versions {
+		if constraint.Check(version) {
+			current = version
+
+			break
+		}
+	}
+
+	latest := policy.Select(level, prerelease, current, versions)
+	if latest == nil || constraint.Check(latest) {
+		return nil
+	}
+
+	allowed, err := constraint.Allow(latest)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"constraint": constraint,
+			"version":    latest,
+			"error":      err,
+		}).Debug("cannot rewrite constraint")
+
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"constraint": constraint,
+		"new":        allowed,
+	}).Debug("found latest change")
+
+	return &Change{
+		line:       constraint.String(),
+		NewLine:    allowed.String(),
+		version:    current,
+		newVersion: latest,
+	}
+}
+
+// setModuleVersion rewrites the version attribute of the module block
+// called name from the string from to to, leaving the rest of data intact.
+func setModuleVersion(data []byte, name, from, to string) ([]byte, error) {
+	return setModuleAttribute(data, name, "version", from, to)
+}
+
+// setModuleSource rewrites the source attribute of the module block called
+// name from the string from to to, leaving the rest of data intact.
+func setModuleSource(data []byte, name, from, to string) ([]byte, error) {
+	return setModuleAttribute(data, name, "source", from, to)
+}
+
+func setModuleAttribute(data []byte, name, attribute, from, to string) ([]byte, error) {
+	file, diags := hclwrite.ParseConfig(data, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	block := file.Body().FirstMatchingBlock("module", []string{name})
+	if block == nil {
+		return nil, fmt.Errorf("cannot find module %s", name)
+	}
+
+	attr := block.Body().GetAttribute(attribute)
+	if attr == nil || !replaceVersion(attr.Expr().BuildTokens(nil), from, to) {
+		return nil, fmt.Errorf("cannot find %s %s of module %s", attribute, from, name)
+	}
+
+	return file.Bytes(), nil
+}
+
+// setProviderVersion rewrites the version constraint of the named provider
+// in every required_providers block of data from the string from to to.
+func setProviderVersion(data []byte, name, from, to string) ([]byte, error) {
+	file, diags := hclwrite.ParseConfig(data, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	found := false
+
+	for _, block := range file.Body().Blocks() {
+		if block.Type() != "terraform" {
+			continue
+		}
+
+		for _, inner := range block.Body().Blocks() {
+			if inner.Type() != "required_providers" {
+				continue
+			}
+
+			attr := inner.Body().GetAttribute(name)
+			if attr == nil {
+				continue
+			}
+
+			if replaceVersion(attr.Expr().BuildTokens(nil), from, to) {
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("cannot find version %s of provider %s", from, name)
+	}
+
+	return file.Bytes(), nil
+}
+
+// replaceVersion replaces from with to inside a quoted string, such as the
+// version or source of a module, or inside the version string of a
+// required_providers entry, either `name = "version"` or
+// `name = { version = "version" }`. The tokens are edited in place.
+func replaceVersion(tokens hclwrite.Tokens, from, to string) bool {
+	literal := -1
+
+	switch {
+	case len(tokens) == 3 && tokens[0].Type == hclsyntax.TokenOQuote && tokens[1].Type == hclsyntax.TokenQuotedLit:
+		literal = 1
+	default:
+		for i := 0; i+3 < len(tokens); i++ {
+			if tokens[i].Type != hclsyntax.TokenIdent || string(tokens[i].Bytes) != "version" {
+				continue
+			}
+
+			if tokens[i+1].Type != hclsyntax.TokenEqual && tokens[i+1].Type != hclsyntax.TokenColon {
+				continue
+			}
+
+			if tokens[i+2].Type == hclsyntax.TokenOQuote && tokens[i+3].Type == hclsyntax.TokenQuotedLit {
+				literal = i + 3
+			}
+
+			break
+		}
+	}
+
+	if literal < 0 || string(tokens[literal].Bytes) != from {
+		return false
+	}
+
+	tokens[literal].Bytes = []byte(to)
+
+	return true
+}
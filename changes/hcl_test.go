@@ -0,0 +1,368 @@
+package changes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func registry(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/providers/hashicorp/aws":
+			fmt.Fprint(w, `{"source": "https://github.com/hashicorp/terraform-provider-aws"}`)
+		case "/v1/providers/hashicorp/aws/versions":
+			fmt.Fprint(w, `{"versions": [{"version": "5.1.0"}, {"version": "5.10.0"}, {"version": "4.67.0"}]}`)
+		case "/v1/modules/terraform-aws-modules/vpc/aws":
+			fmt.Fprint(w, `{"source": "https://github.com/terraform-aws-modules/terraform-aws-vpc", "versions": ["4.0.2", "5.0.0", "5.1.2", "6.0.0-beta1"]}`)
+		case "/v1/providers/hashicorp/random":
+			fmt.Fprint(w, `{"source": "https://github.com/hashicorp/terraform-provider-random"}`)
+		case "/v1/providers/hashicorp/random/versions":
+			fmt.Fprint(w, `{"versions": [{"version": "3.5.1"}, {"version": "3.4.0"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	registryURL := terraform.RegistryURL
+	terraform.RegistryURL = srv.URL
+
+	t.Cleanup(func() {
+		srv.Close()
+		terraform.RegistryURL = registryURL
+	})
+
+	return srv
+}
+
+func TestParseHCLConstraints(t *testing.T) {
+	registry(t)
+
+	cases := []struct {
+		name string
+		file string
+		want string
+	}{
+		{
+			name: "modules and providers",
+			file: heredoc.Doc(`
+				terraform {
+				  required_version = "1.5.0"
+
+				  required_providers {
+				    aws = {
+				      source  = "hashicorp/aws"
+				      version = "~> 4.0" # keep me
+				    }
+				    random = "= 3.4.0"
+				  }
+				}
+
+				module "vpc" {
+				  source  = "terraform-aws-modules/vpc/aws"
+				  version = ">= 4.0, < 5.0"
+				}
+
+				resource "aws_instance" "this" {
+				  tags = {
+				    Version = "5.1.0"
+				  }
+				}
+			`),
+			want: heredoc.Doc(`
+				terraform {
+				  required_version = "1.5.0"
+
+				  required_providers {
+				    aws = {
+				      source  = "hashicorp/aws"
+				      version = "~> 5.0" # keep me
+				    }
+				    random = "= 3.5.1"
+				  }
+				}
+
+				module "vpc" {
+				  source  = "terraform-aws-modules/vpc/aws"
+				  version = ">= 4.0, < 6.0"
+				}
+
+				resource "aws_instance" "this" {
+				  tags = {
+				    Version = "5.1.0"
+				  }
+				}
+			`),
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			file := generateFile(t, test.file)
+			defer os.Remove(file)
+
+			changes, lines := parseHCL(file, policy.Policy{})
+			if !assert.Len(t, changes, 3, test.name) {
+				return
+			}
+
+			assert.Equal(t, "vpc", changes[0].Module, test.name)
+			assert.Equal(t, 15, changes[0].lineNumber, test.name)
+			assert.Equal(t, "4.0.2", changes[0].version.String(), test.name)
+			assert.Equal(t, "5.1.2", changes[0].newVersion.String(), test.name)
+			assert.Equal(t, "aws", changes[1].Module, test.name)
+			assert.Equal(t, 7, changes[1].lineNumber, test.name)
+			assert.Equal(t, "4.67.0", changes[1].version.String(), test.name)
+			assert.Equal(t, "5.10.0", changes[1].newVersion.String(), test.name)
+			assert.Equal(t, "https://github.com/hashicorp/terraform-provider-aws", changes[1].Source, test.name)
+			assert.Equal(t, "random", changes[2].Module, test.name)
+			assert.Equal(t, 9, changes[2].lineNumber, test.name)
+			assert.Equal(t, "= 3.5.1", changes[2].NewLine, test.name)
+			assert.Equal(t, map[int]struct{}{7: {}, 9: {}, 15: {}}, lines, test.name)
+
+			for _, change := range changes {
+				change.Apply()
+			}
+
+			data, err := os.ReadFile(file)
+			assert.Nil(t, err, test.name)
+			assert.Equal(t, test.want, string(data), test.name)
+		})
+	}
+}
+
+func TestParseHCLGitSources(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo, _ := gitRepo(t, "v1.0.0", "v1.1.0", "v2.0.0", "release-3")
+
+	bare := filepath.Join(t.TempDir(), "modules.git")
+
+	out, err := exec.Command("git", "clone", "-q", "--bare", repo, bare).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git clone: %s: %v", out, err)
+	}
+
+	file := generateFile(t, heredoc.Doc(`
+		module "vpc" {
+		  source = "git::file://`+bare+`//modules/vpc?depth=1&ref=v1.0.0"
+		}
+
+		module "main" {
+		  source = "git::file://`+bare+`?ref=main"
+		}
+
+		module "local" {
+		  source = "./modules/local"
+		}
+	`))
+	defer os.Remove(file)
+
+	patch, err := policy.New("patch", "same-track", []policy.Override{
+		{Source: "terraform-source", Level: "minor"},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	changes, lines := parseHCL(file, patch)
+	if !assert.Len(t, changes, 1) {
+		return
+	}
+
+	assert.Equal(t, "vpc", changes[0].Module)
+	assert.Equal(t, 2, changes[0].lineNumber)
+	assert.Equal(t, "1.1.0", changes[0].newVersion.String())
+	assert.Equal(t, "terraform-source", changes[0].Report().Type)
+	assert.Equal(t, map[int]struct{}{2: {}, 6: {}}, lines)
+
+	changes[0].Apply()
+
+	data, err := os.ReadFile(file)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Contains(t, string(data), `  source = "git::file://`+bare+`//modules/vpc?depth=1&ref=v1.1.0"`)
+	assert.Contains(t, string(data), `  source = "git::file://`+bare+`?ref=main"`)
+}
+
+func TestParseHCLInvalid(t *testing.T) {
+	file := generateFile(t, heredoc.Doc(`
+		module "vpc" {
+		  source  = "terraform-aws-modules/vpc/aws"
+		  version = {{ .Version }}
+		}
+	`))
+	defer os.Remove(file)
+
+	changes, lines := parseHCL(file, policy.Policy{})
+	assert.Nil(t, changes)
+	assert.Nil(t, lines)
+
+	changes, lines = parseHCL(filepath.Join(t.TempDir(), "missing.tf"), policy.Policy{})
+	assert.Nil(t, changes)
+	assert.Nil(t, lines)
+}
+
+func TestSetModuleVersion(t *testing.T) {
+	data := heredoc.Doc(`
+		# vpc and eks are both on 1.2.3
+		module "vpc" {
+		  source  = "terraform-aws-modules/vpc/aws"
+		  version = "1.2.3" # pinned until the migration
+		}
+
+		module "eks" {
+		  source  = "terraform-aws-modules/eks/aws"
+		  version = "1.2.3"
+
+		  tags = {
+		    Version = "1.2.3"
+		  }
+		}
+	`)
+
+	cases := []struct {
+		name   string
+		module string
+		from   string
+		to     string
+		want   string
+		err    bool
+	}{
+		{
+			name:   "only the named module is updated",
+			module: "eks",
+			from:   "1.2.3",
+			to:     "2.0.0",
+			want: heredoc.Doc(`
+				# vpc and eks are both on 1.2.3
+				module "vpc" {
+				  source  = "terraform-aws-modules/vpc/aws"
+				  version = "1.2.3" # pinned until the migration
+				}
+
+				module "eks" {
+				  source  = "terraform-aws-modules/eks/aws"
+				  version = "2.0.0"
+
+				  tags = {
+				    Version = "1.2.3"
+				  }
+				}
+			`),
+		},
+		{
+			name:   "comments are kept",
+			module: "vpc",
+			from:   "1.2.3",
+			to:     "~> 2.0",
+			want: heredoc.Doc(`
+				# vpc and eks are both on 1.2.3
+				module "vpc" {
+				  source  = "terraform-aws-modules/vpc/aws"
+				  version = "~> 2.0" # pinned until the migration
+				}
+
+				module "eks" {
+				  source  = "terraform-aws-modules/eks/aws"
+				  version = "1.2.3"
+
+				  tags = {
+				    Version = "1.2.3"
+				  }
+				}
+			`),
+		},
+		{
+			name:   "missing module",
+			module: "rds",
+			from:   "1.2.3",
+			to:     "2.0.0",
+			err:    true,
+		},
+		{
+			name:   "version changed on disk",
+			module: "vpc",
+			from:   "1.0.0",
+			to:     "2.0.0",
+			err:    true,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := setModuleVersion([]byte(data), test.module, test.from, test.to)
+			if test.err {
+				assert.NotNil(t, err, test.name)
+
+				return
+			}
+
+			if assert.Nil(t, err, test.name) {
+				assert.Equal(t, test.want, string(got), test.name)
+			}
+		})
+	}
+}
+
+func TestConstraintChangeLevel(t *testing.T) {
+	versions := []*semver.Version{
+		semver.MustParse("6.0.0-beta.1"),
+		semver.MustParse("5.2.0-rc.2"),
+		semver.MustParse("5.1.0"),
+		semver.MustParse("4.2.0"),
+		semver.MustParse("4.0.3"),
+		semver.MustParse("4.0.1"),
+	}
+
+	cases := []struct {
+		name       string
+		constraint string
+		level      policy.Level
+		prerelease policy.Prerelease
+		want       string
+	}{
+		{name: "major", constraint: "4.0.1", level: policy.Major, want: "5.1.0"},
+		{name: "minor", constraint: "4.0.1", level: policy.Minor, want: "4.2.0"},
+		{name: "patch", constraint: "4.0.1", level: policy.Patch, want: "4.0.3"},
+		{name: "patch within the constraint", constraint: "~> 4.0.0", level: policy.Patch},
+		{name: "minor outside the constraint", constraint: "~> 4.0.0", level: policy.Minor, want: "~> 4.2.0"},
+		{name: "always", constraint: "4.0.1", level: policy.Major, prerelease: policy.Always, want: "6.0.0-beta.1"},
+		{name: "same track", constraint: "5.2.0-rc.1", level: policy.Major, want: "5.2.0-rc.2"},
+		{name: "never", constraint: "5.2.0-rc.1", level: policy.Major, prerelease: policy.Never},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			constraint, err := terraform.ParseConstraint(test.constraint)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			change := constraintChange(constraint, versions, test.level, test.prerelease)
+			if test.want == "" {
+				assert.Nil(t, change, test.name)
+
+				return
+			}
+
+			if assert.NotNil(t, change, test.name) {
+				assert.Equal(t, test.want, change.NewLine, test.name)
+			}
+		})
+	}
+}
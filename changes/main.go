@@ -1,21 +1,21 @@
 package changes
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
-	"regexp"
-	"sort"
 	"strings"
 	"sync"
 
 	"github.com/Masterminds/semver/v3"
-	"github.com/google/go-github/v50/github"
-	"github.com/mhristof/bump/awsdata"
+	"github.com/mhristof/bump/gomod"
+	"github.com/mhristof/bump/helm"
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/precommit"
+	"github.com/mhristof/bump/updater"
+	"github.com/mhristof/bump/updater/oci"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/oauth2"
 )
 
 type Changes []*Change
@@ -28,6 +28,14 @@ func (f Format) String() string {
 		return "string"
 	case Terraform:
 		return "terraform"
+	case TerraformProvider:
+		return "terraform-provider"
+	case PreCommit:
+		return "pre-commit"
+	case GoMod:
+		return "gomod"
+	case TerraformSource:
+		return "terraform-source"
 	}
 
 	return "unsupported"
@@ -36,10 +44,41 @@ func (f Format) String() string {
 const (
 	String Format = iota
 	Terraform
+	TerraformProvider
+	PreCommit
+	GoMod
+	TerraformSource
 )
 
+// Kind is what a change moves: the version, only the digest an image tag
+// points to when the tag was rebuilt upstream, nothing but a first digest
+// added to an image that had none, or a value without a version replaced by
+// a newer one.
+type Kind int
+
+const (
+	Version Kind = iota
+	Digest
+	Pin
+	Replace
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Digest:
+		return "digest"
+	case Pin:
+		return "pin"
+	case Replace:
+		return "replace"
+	}
+
+	return "version"
+}
+
 type Change struct {
 	line       string
+	lineNumber int
 	NewLine    string
 	Module     string
 	file       string
@@ -47,6 +86,14 @@ type Change struct {
 	newVersion *semver.Version
 	format     Format
 	Source     string
+	updater    string
+	applied    bool
+	// frozen is the version written next to a NewLine that pins a commit.
+	frozen string
+	kind   Kind
+	// releaseNotes is the compare URL of the source repository, looked up
+	// once by Update.
+	releaseNotes string
 }
 
 func (c Change) String() string {
@@ -54,17 +101,35 @@ func (c Change) String() string {
 	switch c.format {
 	case String:
 		ret = fmt.Sprintf("%s -- %s -> %s", c.file, c.line, c.NewLine)
-	case Terraform:
-		ret = fmt.Sprintf("%s:%s:%s -> %s", c.file, c.Module, c.version, c.newVersion)
+	case Terraform, TerraformSource:
+		ret = fmt.Sprintf("%s:%s:%s -> %s", c.file, c.Module, c.line, c.NewLine)
+	case TerraformProvider:
+		ret = fmt.Sprintf("%s:provider.%s:%s -> %s", c.file, c.Module, c.line, c.NewLine)
+	case PreCommit, GoMod:
+		ret = fmt.Sprintf("%s:%s:%s -> %s", c.file, c.Module, c.line, c.NewLine)
 	}
 
-	if c.Source != "" {
-		ret += " " + githubDiffURL(c.Source, c.version.String(), c.newVersion.String())
+	if c.kind != Version {
+		return ret + " (" + c.kind.String() + ")"
+	}
+
+	if c.releaseNotes != "" {
+		ret += " " + c.releaseNotes
 	}
 
 	return ret
 }
 
+// lookupReleaseNotes finds the compare URL of the change in its source
+// repository. Digest changes and pins keep the same version and have none.
+func (c *Change) lookupReleaseNotes() {
+	if c.Source == "" || c.kind != Version || c.version == nil || c.newVersion == nil {
+		return
+	}
+
+	c.releaseNotes = githubDiffURL(c.Source, c.version.String(), c.newVersion.String())
+}
+
 func githubDiffURL(repo, from, to string) string {
 	urls := []string{
 		fmt.Sprintf("%s/compare/%s...%s", repo, from, to),
@@ -91,51 +156,42 @@ func githubDiffURL(repo, from, to string) string {
 	return ret
 }
 
-func New(src []string) Changes {
+// New returns a change for every line with a version in the files of src.
+// Directories are scanned recursively with filter. Entries of src that are
+// not paths are checked as strings.
+func New(src []string, filter Filter) Changes {
 	ret := Changes{}
 
 	for _, s := range src {
-		_, err := os.Stat(s)
+		info, err := os.Stat(s)
 		if errors.Is(err, os.ErrNotExist) {
 			continue
 		}
 
-		log.WithField("file", s).Debug("file")
+		if err != nil || !info.IsDir() {
+			ret = append(ret, newFile(s)...)
 
-		ret = append(ret, &Change{
-			file: s,
-		})
+			continue
+		}
 
-		data, err := os.ReadFile(s)
+		files, err := filter.Files(s)
 		if err != nil {
-			log.WithField("file", s).Error("Failed to read file")
+			log.WithFields(log.Fields{
+				"dir":   s,
+				"error": err,
+			}).Error("cannot scan directory")
+
 			continue
 		}
 
-		for _, line := range strings.Split(string(data), "\n") {
-			ver := extractVersion(line)
-			if ver != nil {
-				log.WithFields(log.Fields{
-					"string":  s,
-					"version": ver,
-					"line":    line,
-				}).Debug("found version")
-
-				ret = append(ret, &Change{
-					line:    line,
-					version: ver,
-					file:    s,
-				})
-
-				continue
-			}
-
+		for _, file := range files {
+			ret = append(ret, newFile(file)...)
 		}
 	}
 
 	for _, s := range src {
-		_, err := os.Stat(s)
-		if !errors.Is(err, os.ErrNotExist) {
+		info, err := os.Stat(s)
+		if err == nil && !info.IsDir() {
 			log.WithField("file", s).Debug("file")
 
 			ret = append(ret, &Change{
@@ -145,7 +201,7 @@ func New(src []string) Changes {
 
 		log.WithField("string", s).Debug("Checking string")
 
-		ver := extractVersion(s)
+		ver := updater.ExtractVersion(s)
 		if ver != nil {
 			log.WithField("string", s).Debug("Found version")
 			ret = append(ret, &Change{
@@ -160,287 +216,310 @@ func New(src []string) Changes {
 	return ret
 }
 
-func extractVersion(line string) *semver.Version {
-	semverRegex := regexp.MustCompile(`v?\d+\.\d+\.\d+`)
+func newFile(path string) Changes {
+	log.WithField("file", path).Debug("file")
 
-	if semverRegex.MatchString(line) {
-		return semver.MustParse(semverRegex.FindString(line))
+	ret := Changes{
+		&Change{
+			file: path,
+		},
 	}
 
-	return nil
-}
-
-func (c *Changes) Update(threads int) {
-	parsed := map[string]struct{}{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.WithField("file", path).Error("Failed to read file")
 
-	aws := awsdata.New(threads)
+		return ret
+	}
 
-	reDockerhub := regexp.MustCompile(`\w*/\w*:[^\s]*`)
+	if isBinary(data) {
+		log.WithField("file", path).Debug("skipping binary file")
 
-	var changed Changes
+		return nil
+	}
 
-	for _, change := range *c {
-		log.WithField("change", change).Trace("checking change")
+	for i, line := range strings.Split(string(data), "\n") {
+		ver := updater.ExtractVersion(line)
+		if ver == nil {
+			continue
+		}
 
-		switch {
-		case strings.Contains(change.line, "dkr.ecr"):
-			log.WithFields(log.Fields{
-				"change":         change,
-				"line":           change.line,
-				"change.version": change.version,
-			}).Debug("Updating ECR link")
-
-			newContents, newVersion := updateECR(aws, change.line, change.version)
-			if newVersion == nil {
-				continue
-			}
-			change.NewLine = newContents
-			change.newVersion = newVersion
-			changed = append(changed, change)
+		log.WithFields(log.Fields{
+			"string":  path,
+			"version": ver,
+			"line":    line,
+		}).Debug("found version")
 
-		case strings.Contains(change.line, "ghcr.io"):
+		ret = append(ret, &Change{
+			line:       line,
+			lineNumber: i + 1,
+			version:    ver,
+			file:       path,
+		})
+	}
 
-			re := regexp.MustCompile(`ghcr.io/([^/]+)/([^/]+):(.+)`)
-			matches := re.FindStringSubmatch(change.line)
+	return ret
+}
 
-			if len(matches) != 4 {
-				log.WithFields(log.Fields{
-					"change": change,
-					"line":   change.line,
-				}).Debug("Failed to parse ghcr.io line")
+// Update resolves the new version of every change with the registered
+// updaters and keeps only the ones that have an update the policy allows.
+func (c *Changes) Update(threads int, p policy.Policy) {
+	files := map[string][]coverage{}
+
+	updaters := updater.New(updater.Options{Threads: threads})
+
+	sources := []fileSource{
+		preCommitSource{},
+		goModSource{proxy: gomod.Environment()},
+		dockerfileSource{updaters: updaters},
+		scanSource{updaters: updaters},
+		chartSource{client: helm.NewClient(oci.NewClient(oci.DockerConfig()))},
+		manifestSource{updaters: updaters},
+		hclSource{},
+	}
 
-				continue
-			}
+	log.WithField("updaters", updaters.Names()).Debug("registered updaters")
 
-			org := matches[1]
-			repo := matches[2]
-			tag := strings.Trim(matches[3], `"`)
+	var changed Changes
 
-			log.WithFields(log.Fields{
-				"change":  change,
-				"line":    change.line,
-				"matches": matches,
-				"org":     org,
-				"repo":    repo,
-				"tag":     tag,
-			}).Debug("Updating ghcr.io link")
-
-			newVersion := githubPackageUpdate(org, repo, semver.MustParse(tag))
-
-			if newVersion == nil {
-				log.WithFields(log.Fields{
-					"change.line": change.line,
-					"tag":         tag,
-				}).Debug("Failed to update ghcr.io link")
-
-				continue
-			}
+	for _, change := range *c {
+		log.WithField("change", change).Trace("checking change")
 
-			log.WithFields(log.Fields{
-				"change.line": change.line,
-				"tag":         tag,
-				"newVersion":  newVersion,
-			}).Debug("Updated ghcr.io link")
+		covered, ok := files[change.file]
+		if !ok {
+			var fileChanges Changes
 
-			change.NewLine = strings.ReplaceAll(change.line, tag, newVersion.String())
+			fileChanges, covered = parseFile(change.file, sources, p)
+			files[change.file] = covered
+			changed = append(changed, fileChanges...)
+		}
 
-			log.WithFields(log.Fields{
-				"change": change,
-			}).Debug("Updated ghcr.io link")
+		if handled(covered, change.lineNumber) {
+			continue
+		}
 
+		u := updaters.Match(change.file, change.line)
+		if u != nil && change.update(u, p) {
 			changed = append(changed, change)
+		}
+	}
 
-		case strings.Contains(change.line, "-ami-"):
-			name := strings.Split(change.line, `"`)[1]
-			log.WithFields(log.Fields{
-				"line": change.line,
-				"name": name,
-			}).Debug("searching for AMI")
-
-			newAMI := aws.ValidAMI(name)
-			if newAMI == "" {
-				log.WithFields(log.Fields{
-					"line": change.line,
-					"name": name,
-				}).Trace("no AMI found")
-
-				continue
-			}
+	for _, change := range changed {
+		change.lookupReleaseNotes()
+	}
 
-			change.NewLine = strings.ReplaceAll(change.line, name, newAMI)
-			changed = append(changed, change)
+	log.WithField("len", len(changed)).Debug("number of changes")
 
-		case strings.Contains(change.line, "https://gitlab.com"):
-			log.WithField("change", change).Debug("Updating gitlab link")
+	*c = changed
+}
 
-		case strings.Contains(change.line, "https://github.com"):
-			log.WithField("change", change).Debug("Updating github link")
+func (c *Change) update(u *updater.Named, p policy.Policy) bool {
+	log.WithFields(log.Fields{
+		"change":  c,
+		"updater": u.Name,
+	}).Debug("Updating line")
 
-			newContents, newVersion := githubUpdate(change.line, change.version)
-			change.NewLine = newContents
-			change.newVersion = newVersion
+	current, versions, err := u.Versions(c.line)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"line":    c.line,
+			"updater": u.Name,
+			"error":   err,
+		}).Debug("cannot retrieve versions")
 
-			changed = append(changed, change)
-			log.WithField("change", change).Debug("Updated github link")
+		return false
+	}
 
-		case reDockerhub.MatchString(change.line):
-			possibleImage := reDockerhub.FindString(change.line)
+	if current == nil {
+		if r, ok := u.Updater.(updater.Replacer); ok {
+			return c.replace(u.Name, r)
+		}
 
-			log.WithFields(log.Fields{
-				"change":        change,
-				"possibleImage": possibleImage,
-			}).Debug("Updating dockerhub link")
+		log.WithFields(log.Fields{
+			"line":    c.line,
+			"updater": u.Name,
+		}).Debug("no current version found")
 
-			contents := dockerHub(possibleImage)
-			if contents == "" {
-				continue
-			}
+		return false
+	}
 
-			change.NewLine = strings.ReplaceAll(change.line, possibleImage, contents)
-			changed = append(changed, change)
-		case strings.HasSuffix(change.file, ".tf"):
-			if _, ok := parsed[change.file]; ok {
-				log.WithField("file", change.file).Debug("already parsed with HCL")
+	pinner, canPin := u.Updater.(updater.Pinner)
+	pin := canPin && (p.Pin || updater.HasDigest(c.line))
 
-				continue
-			}
-			tfChanges := parseHCL(change.file)
+	newVersion := p.Select(c.file, u.Name, current, versions)
+	newLine := c.line
+	kind := Version
 
-			log.WithField("changes", tfChanges).Debug("Found HCL changes")
-			parsed[change.file] = struct{}{}
-			changed = append(changed, tfChanges...)
-		}
-	}
+	switch {
+	case newVersion != nil:
+		newLine = u.Rewrite(c.line, current, newVersion)
+	case pin:
+		newVersion = current
+		kind = Pin
 
-	log.WithField("len", len(changed)).Debug("number of changes")
+		if updater.HasDigest(c.line) {
+			kind = Digest
+		}
+	default:
+		log.WithFields(log.Fields{
+			"line":    c.line,
+			"updater": u.Name,
+		}).Debug("no newer version found")
 
-	*c = changed
-}
+		return false
+	}
 
-func githubUpdate(line string, version *semver.Version) (string, *semver.Version) {
-	ctx := context.Background()
+	if pin {
+		newLine, err = pinner.Pin(newLine)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"line":    c.line,
+				"updater": u.Name,
+				"error":   err,
+			}).Warning("cannot resolve digest")
 
-	token := os.Getenv("GITHUB_READONLY_TOKEN")
+			return false
+		}
+	}
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tc := oauth2.NewClient(ctx, ts)
+	if newLine == c.line {
+		return false
+	}
 
-	client := github.NewClient(tc)
+	c.version = current
+	c.newVersion = newVersion
+	c.NewLine = newLine
+	c.updater = u.Name
+	c.kind = kind
 
-	repoRegex := regexp.MustCompile(`https://github.com/([a-zA-Z0-9-]+)/([a-zA-Z0-9-]+)`)
+	if r, ok := u.Updater.(updater.Repository); ok {
+		c.Source = r.Repository(c.line)
+	}
 
-	matches := repoRegex.FindStringSubmatch(line)
+	log.WithFields(log.Fields{
+		"change":  c,
+		"updater": u.Name,
+	}).Debug("Updated line")
 
-	owner := matches[1]
-	repo := matches[2]
+	return true
+}
 
-	// get the repo
-	releases, foo, bar := client.Repositories.ListReleases(context.TODO(), owner, repo, nil)
+// replace updates a line without a version to the newest value r has. The
+// change has no versions, so the policy levels do not apply to it.
+func (c *Change) replace(name string, r updater.Replacer) bool {
+	newLine, err := r.Replace(c.line)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"line":    c.line,
+			"updater": name,
+			"error":   err,
+		}).Warning("cannot replace value")
 
-	log.WithFields(log.Fields{
-		"len":   len(releases),
-		"repo":  repo,
-		"owner": owner,
-		"foo":   foo,
-		"bar":   bar,
-	}).Debug("Found releases")
-	semverReleases := make([]*semver.Version, len(releases))
+		return false
+	}
 
-	for i, release := range releases {
-		log.WithField("release", release.GetTagName()).Trace("Release")
-		semverReleases[i] = semver.MustParse(release.GetTagName())
+	if newLine == c.line {
+		return false
 	}
 
-	sort.Sort(semver.Collection(semverReleases))
+	c.version = nil
+	c.NewLine = newLine
+	c.updater = name
+	c.kind = Replace
 
 	log.WithFields(log.Fields{
-		"len": len(semverReleases),
-	}).Debug("Sorted releases")
+		"change":  c,
+		"updater": name,
+	}).Debug("Replaced line")
+
+	return true
+}
+
+// Render returns data with the change applied.
+func (c Change) Render(data []byte) ([]byte, error) {
+	switch c.format {
+	case String:
+		if c.lineNumber > 0 {
+			return replaceLine(data, c.lineNumber, c.line, c.NewLine)
+		}
 
-	for i := len(semverReleases) - 1; i >= 0; i-- {
+		return []byte(strings.ReplaceAll(string(data), c.line, c.NewLine)), nil
+	case Terraform:
 		log.WithFields(log.Fields{
-			"version": semverReleases[i].String(),
-			"i":       semverReleases[i].String(),
-		}).Debug("Checking version")
+			"file":    c.file,
+			"version": c.version,
+			"new":     c.newVersion,
+		}).Debug("Updating terraform file")
 
-		if semverReleases[i].Compare(version) > 0 {
-			log.WithField("version", semverReleases[i].String()).Debug("Found version")
-			return strings.ReplaceAll(line, version.String(), semverReleases[i].String()), semverReleases[i]
+		ret, err := setModuleVersion(data, c.Module, c.line, c.NewLine)
+		if err != nil {
+			return nil, fmt.Errorf("cannot update terraform module %s: %w", c.Module, err)
 		}
-	}
 
-	return line, nil
-}
+		return ret, nil
+	case TerraformSource:
+		ret, err := setModuleSource(data, c.Module, c.line, c.NewLine)
+		if err != nil {
+			return nil, fmt.Errorf("cannot update terraform module %s: %w", c.Module, err)
+		}
 
-func githubPackageUpdate(org, packageName string, version *semver.Version) *semver.Version {
-	ctx := context.Background()
+		return ret, nil
+	case TerraformProvider:
+		log.WithFields(log.Fields{
+			"file":     c.file,
+			"provider": c.Module,
+			"version":  c.line,
+			"new":      c.NewLine,
+		}).Debug("Updating terraform provider")
 
-	token := os.Getenv("GITHUB_READONLY_TOKEN")
+		ret, err := setProviderVersion(data, c.Module, c.line, c.NewLine)
+		if err != nil {
+			return nil, fmt.Errorf("cannot update terraform provider %s: %w", c.Module, err)
+		}
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tc := oauth2.NewClient(ctx, ts)
+		return ret, nil
+	case PreCommit:
+		ret, err := precommit.SetRev(data, c.Module, c.line, c.NewLine, c.frozen)
+		if err != nil {
+			return nil, fmt.Errorf("cannot update pre-commit repo %s: %w", c.Module, err)
+		}
 
-	client := github.NewClient(tc)
+		return ret, nil
+	case GoMod:
+		ret, err := gomod.SetVersion(data, c.Module, c.line, c.NewLine)
+		if err != nil {
+			return nil, fmt.Errorf("cannot update go module %s: %w", c.Module, err)
+		}
 
-	for page := 1; ; page++ {
-		versions, resp, err := client.Organizations.PackageGetAllVersions(context.Background(), org, "container", packageName, &github.PackageListOptions{
-			ListOptions: github.ListOptions{
-				Page:    page,
-				PerPage: 1000,
-			},
-		})
+		return ret, nil
+	}
 
-		log.WithFields(log.Fields{
-			"len":           len(versions),
-			"org":           org,
-			"package":       packageName,
-			"resp":          resp,
-			"err":           err,
-			"resp.LastPage": resp.LastPage,
-		}).Debug("found package releases")
-
-		for _, packageVersion := range versions {
-			if len(packageVersion.Metadata.Container.Tags) == 0 {
-				continue
-			}
+	return nil, fmt.Errorf("unsupported format %s", c.format)
+}
 
-			for _, tag := range packageVersion.Metadata.Container.Tags {
-				ver, err := semver.NewVersion(tag)
-				if err != nil {
-					log.WithFields(log.Fields{
-						"tag":     tag,
-						"err":     err,
-						"org":     org,
-						"package": packageName,
-					}).Debug("failed to parse tag")
-					continue
-				}
-
-				if ver.Compare(version) > 0 {
-					log.WithFields(log.Fields{
-						"tag":     tag,
-						"ver":     ver,
-						"package": packageName,
-					}).Debug("found version")
-					return ver
-				}
-
-				if ver.Compare(version) <= 0 {
-					return nil
-				}
+// replaceLine returns data with the 1-based line number set to to. It fails
+// when the line is no longer from, so an edit is never written over a line
+// that changed since it was parsed.
+func replaceLine(data []byte, number int, from, to string) ([]byte, error) {
+	lines := strings.Split(string(data), "\n")
+	if number > len(lines) {
+		return nil, fmt.Errorf("cannot find line %d", number)
+	}
 
-			}
-		}
+	if lines[number-1] != from {
+		return nil, fmt.Errorf("line %d is %q, expected %q", number, lines[number-1], from)
 	}
 
-	return nil
+	lines[number-1] = to
+
+	return []byte(strings.Join(lines, "\n")), nil
 }
 
-func (c Change) Apply() {
+// goSumWarnings holds the go.mod files that were told to need go mod tidy,
+// so the warning is logged once per file.
+var goSumWarnings sync.Map
+
+func (c *Change) Apply() {
 	if c.file == "" {
 		return
 	}
@@ -450,17 +529,14 @@ func (c Change) Apply() {
 		panic(err)
 	}
 
-	switch c.format {
-	case String:
-		data = []byte(strings.ReplaceAll(string(data), c.line, c.NewLine))
-	case Terraform:
+	data, err = c.Render(data)
+	if err != nil {
 		log.WithFields(log.Fields{
-			"file":    c.file,
-			"version": c.version,
-			"new":     c.newVersion,
-		}).Debug("Updating terraform file")
+			"file":  c.file,
+			"error": err,
+		}).Error("cannot apply change")
 
-		data = []byte(strings.ReplaceAll(string(data), c.version.String(), c.newVersion.String()))
+		return
 	}
 
 	fileStat, err := os.Stat(c.file)
@@ -468,7 +544,23 @@ func (c Change) Apply() {
 		panic(err)
 	}
 
-	os.WriteFile(c.file, data, fileStat.Mode())
+	err = os.WriteFile(c.file, data, fileStat.Mode())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  c.file,
+			"error": err,
+		}).Error("cannot write file")
+
+		return
+	}
+
+	c.applied = true
 
 	log.WithField("file", c.file).Info("Updated file")
+
+	if c.format == GoMod {
+		if _, warned := goSumWarnings.LoadOrStore(c.file, struct{}{}); !warned {
+			log.WithField("file", c.file).Warning("go.sum is not updated, run go mod tidy")
+		}
+	}
 }
@@ -2,39 +2,16 @@ package changes
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/updater"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestGithubUpdate(t *testing.T) {
-	cases := []struct {
-		name       string
-		line       string
-		file       string
-		newLine    string
-		newVersion *semver.Version
-	}{
-		{
-			name:       "simple github url",
-			line:       "https://github.com/mhristof/bump-semver/releases/download/v0.1.0/semver",
-			newLine:    "https://github.com/mhristof/bump-semver/releases/download/v0.17.1/semver",
-			newVersion: semver.MustParse("v0.17.1"),
-		},
-	}
-
-	// log.SetLevel(log.DebugLevel)
-	for _, test := range cases {
-		t.Run(test.name, func(t *testing.T) {
-			line, newVersion := githubUpdate(test.line, extractVersion(test.line))
-			assert.Equal(t, test.newLine, line, test.name)
-			assert.Equal(t, test.newVersion, newVersion, test.name)
-		})
-	}
-}
-
 func TestParseHCL(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -59,7 +36,7 @@ func TestParseHCL(t *testing.T) {
 
 	for _, test := range cases {
 		t.Run(test.name, func(t *testing.T) {
-			changes := parseHCL(test.file)
+			changes, _ := parseHCL(test.file, policy.Policy{})
 
 			assert.Equal(t, test.module, changes[0].Module, test.name)
 			assert.Equal(t, test.oldVersion, changes[0].version.String(), test.name)
@@ -68,6 +45,49 @@ func TestParseHCL(t *testing.T) {
 	}
 }
 
+// datedUpdater replaces the date stamped image names, which have no version.
+type datedUpdater struct{}
+
+func (datedUpdater) Match(file, line string) bool {
+	return strings.Contains(line, "server-")
+}
+
+func (datedUpdater) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	return nil, nil, nil
+}
+
+func (datedUpdater) Rewrite(line string, current, next *semver.Version) string {
+	return line
+}
+
+func (datedUpdater) Replace(line string) (string, error) {
+	return strings.ReplaceAll(line, "server-20230516", "server-20240207"), nil
+}
+
+func TestUpdateReplace(t *testing.T) {
+	u := &updater.Named{Name: "ami", Updater: datedUpdater{}}
+
+	change := &Change{
+		line:    `ami = "ubuntu-jammy-22.04-amd64-server-20230516"`,
+		version: semver.MustParse("22.04"),
+		file:    "main.tf",
+	}
+
+	if !assert.True(t, change.update(u, policy.Policy{Level: policy.Patch})) {
+		return
+	}
+
+	assert.Equal(t, `ami = "ubuntu-jammy-22.04-amd64-server-20240207"`, change.NewLine)
+
+	report := change.Report()
+	assert.Equal(t, "replace", report.Kind)
+	assert.Equal(t, "", report.Current)
+	assert.Equal(t, "", report.Proposed)
+
+	current := &Change{line: `ami = "ubuntu-jammy-22.04-amd64-server-20240207"`, file: "main.tf"}
+	assert.False(t, current.update(u, policy.Policy{}))
+}
+
 func generateFile(t *testing.T, content string) string {
 	f, err := os.CreateTemp("", "test")
 	if err != nil {
@@ -0,0 +1,103 @@
+package changes
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mhristof/bump/manifest"
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+// manifestSource updates the images of YAML files.
+type manifestSource struct {
+	updaters updater.Set
+}
+
+func (s manifestSource) Handles(path string) bool {
+	return manifest.IsManifest(path)
+}
+
+func (s manifestSource) Parse(path string, p policy.Policy) (Changes, coverage) {
+	changes, lines := parseManifest(path, s.updaters, p)
+
+	return changes, coverLines(lines)
+}
+
+// parseManifest returns a change for every image of the YAML file at path
+// that the registry updater handling it has a newer tag for, and the lines
+// the images are written in. Files that are not valid YAML, like Helm
+// templates, return no lines so they are left to the line updaters.
+func parseManifest(path string, updaters updater.Set, p policy.Policy) (Changes, map[int]struct{}) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.WithField("file", path).Error("Failed to read file")
+
+		return nil, nil
+	}
+
+	images, err := manifest.Parse(data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  path,
+			"error": err,
+		}).Debug("cannot parse yaml")
+
+		return nil, nil
+	}
+
+	var ret Changes
+
+	lines := map[int]struct{}{}
+	seen := map[string]struct{}{}
+
+	for _, image := range images {
+		lines[image.Line] = struct{}{}
+
+		reference := image.Reference()
+
+		u := updaters.Match(path, reference)
+		if u == nil {
+			log.WithField("image", reference).Debug("no updater for image")
+
+			continue
+		}
+
+		line := reference
+		if image.Digest != "" {
+			line += "@" + image.Digest
+		}
+
+		change := &Change{
+			line: line,
+			file: path,
+		}
+
+		if !change.update(u, p) {
+			continue
+		}
+
+		newReference, digest, _ := strings.Cut(change.NewLine, "@")
+		tag := newReference[strings.LastIndex(newReference, ":")+1:]
+
+		for _, edit := range image.Rewrite(tag, digest) {
+			c := *change
+			c.line = edit.Text
+			c.NewLine = edit.NewText
+			c.lineNumber = edit.Line
+
+			key := strconv.Itoa(c.lineNumber) + "\x00" + c.NewLine
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			ret = append(ret, &c)
+		}
+	}
+
+	return ret, lines
+}
@@ -0,0 +1,139 @@
+package changes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/updater"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseManifest(t *testing.T) {
+	digest := func(c string) string { return "sha256:" + strings.Repeat(c, 64) }
+
+	path := filepath.Join(t.TempDir(), "values.yaml")
+
+	data := `# app values
+app:
+  image: ghcr.io/org/app:v1.2.3   # keep the comment
+redis:
+  image:
+    repository: bitnami/redis
+    tag: "7.0.11"
+sidecar:
+  image: "nginx:1.25@` + digest("0") + `"
+`
+
+	err := os.WriteFile(path, []byte(data), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updaters := updater.Set{
+		{Name: "ghcr", Updater: registryUpdater{prefix: "ghcr.io/", versions: map[string][]string{
+			"ghcr.io/org/app": {"v1.3.0"},
+		}}},
+		{Name: "dockerhub", Updater: pinningUpdater{
+			registryUpdater: registryUpdater{prefix: "/", versions: map[string][]string{
+				"bitnami/redis": {"7.2.0"},
+				"library/nginx": {"1.25"},
+			}},
+			digests: map[string]string{"library/nginx:1.25": digest("a")},
+		}},
+	}
+
+	changes, lines := parseManifest(path, updaters, policy.Policy{})
+
+	assert.Equal(t, map[int]struct{}{3: {}, 7: {}, 9: {}}, lines)
+
+	for _, change := range changes {
+		change.Apply()
+	}
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `# app values
+app:
+  image: ghcr.io/org/app:v1.3.0   # keep the comment
+redis:
+  image:
+    repository: bitnami/redis
+    tag: "7.2.0"
+sidecar:
+  image: "nginx:1.25@`+digest("a")+`"
+`, string(updated))
+
+	changes, lines = parseManifest(path, updaters, policy.Policy{})
+	assert.Len(t, changes, 0)
+	assert.Len(t, lines, 3)
+}
+
+func TestParseManifestTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployment.yaml")
+
+	err := os.WriteFile(path, []byte("spec:\n  {{- toYaml .Values.spec | nindent 2 }}\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, lines := parseManifest(path, nil, policy.Policy{})
+	assert.Nil(t, changes)
+	assert.Nil(t, lines)
+}
+
+func TestParseManifestSharedTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yaml")
+
+	data := `app:
+  image:
+    repository: org/app
+    tag: "1.2.3"
+worker:
+  image:
+    repository: org/worker
+    tag: "1.2.3"
+`
+
+	err := os.WriteFile(path, []byte(data), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updaters := updater.Set{
+		{Name: "dockerhub", Updater: registryUpdater{prefix: "/", versions: map[string][]string{
+			"org/app":    {"1.3.0"},
+			"org/worker": {"1.2.3"},
+		}}},
+	}
+
+	changes, _ := parseManifest(path, updaters, policy.Policy{})
+	if !assert.Len(t, changes, 1) {
+		return
+	}
+
+	changes[0].Apply()
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `app:
+  image:
+    repository: org/app
+    tag: "1.3.0"
+worker:
+  image:
+    repository: org/worker
+    tag: "1.2.3"
+`, string(updated))
+
+	_, err = changes[0].Render(updated)
+	assert.NotNil(t, err)
+}
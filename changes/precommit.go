@@ -0,0 +1,140 @@
+package changes
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/precommit"
+	"github.com/mhristof/bump/updater/git"
+	log "github.com/sirupsen/logrus"
+)
+
+// preCommitSource updates the repos of pre-commit configurations, which it
+// handles whole.
+type preCommitSource struct{}
+
+func (preCommitSource) Handles(path string) bool {
+	return precommit.IsConfig(path)
+}
+
+func (preCommitSource) Parse(path string, p policy.Policy) (Changes, coverage) {
+	return parsePreCommit(path, p), coverAll()
+}
+
+// parsePreCommit returns a change for every repo of the pre-commit
+// configuration at path that has a newer tag the policy allows. With
+// p.Freeze the revs are pinned to the commit of the tag.
+func parsePreCommit(path string, p policy.Policy) Changes {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.WithField("file", path).Error("Failed to read file")
+
+		return nil
+	}
+
+	repos, err := precommit.Parse(data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  path,
+			"error": err,
+		}).Error("cannot parse pre-commit config")
+
+		return nil
+	}
+
+	var ret Changes
+
+	for _, repo := range repos {
+		change := preCommitChange(path, repo, p)
+		if change == nil {
+			continue
+		}
+
+		ret = append(ret, change)
+	}
+
+	return ret
+}
+
+func preCommitChange(path string, repo precommit.Repo, p policy.Policy) *Change {
+	current := repo.Version()
+	if current == nil {
+		log.WithFields(log.Fields{
+			"repo": repo.URL,
+			"rev":  repo.Rev,
+		}).Debug("rev is not a version")
+
+		return nil
+	}
+
+	tags, err := git.Tags(repo.URL)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"repo":  repo.URL,
+			"error": err,
+		}).Debug("cannot retrieve tags")
+
+		return nil
+	}
+
+	commits := map[string]string{}
+
+	var versions []*semver.Version
+
+	for _, tag := range tags {
+		version, err := semver.NewVersion(tag.Name)
+		if err != nil {
+			continue
+		}
+
+		commits[tag.Name] = tag.Commit
+		versions = append(versions, version)
+	}
+
+	next := p.Select(path, PreCommit.String(), current, versions)
+	if next == nil {
+		if !p.Freeze || repo.Frozen != "" {
+			return nil
+		}
+
+		next = current
+	}
+
+	change := &Change{
+		line:       repo.Rev,
+		lineNumber: repo.Line,
+		NewLine:    next.Original(),
+		Module:     repo.URL,
+		file:       path,
+		version:    current,
+		newVersion: next,
+		format:     PreCommit,
+	}
+
+	if p.Freeze {
+		commit, ok := commits[next.Original()]
+		if !ok {
+			log.WithFields(log.Fields{
+				"repo": repo.URL,
+				"tag":  next.Original(),
+			}).Debug("cannot find commit of tag")
+
+			return nil
+		}
+
+		change.NewLine = commit
+		change.frozen = next.Original()
+	}
+
+	if change.NewLine == change.line {
+		return nil
+	}
+
+	if strings.HasPrefix(repo.URL, "https://github.com/") {
+		change.Source = strings.TrimSuffix(repo.URL, ".git")
+	}
+
+	return change
+}
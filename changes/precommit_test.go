@@ -0,0 +1,128 @@
+package changes
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+// gitRepo creates a repository with a commit for every tag and returns its
+// path and the commit of every tag.
+func gitRepo(t *testing.T, tags ...string) (string, map[string]string) {
+	dir := t.TempDir()
+	commits := map[string]string{}
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+
+		return strings.TrimSpace(string(out))
+	}
+
+	git("init", "-q")
+
+	for _, tag := range tags {
+		git("commit", "-q", "--allow-empty", "-m", tag)
+		git("tag", "-a", "-m", tag, tag)
+
+		commits[tag] = git("rev-parse", "HEAD")
+	}
+
+	return dir, commits
+}
+
+func TestParsePreCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo, commits := gitRepo(t, "v1.0.0", "v1.1.0", "v2.0.0", "v2.1.0-rc.1")
+
+	config := "repos:\n" +
+		"  - repo: " + repo + "\n" +
+		"    rev: v1.0.0 # pinned\n" +
+		"    hooks:\n" +
+		"      - id: test\n"
+
+	cases := []struct {
+		name   string
+		config string
+		policy policy.Policy
+		want   string
+	}{
+		{
+			name:   "latest",
+			config: config,
+			want:   "    rev: v2.0.0 # pinned",
+		},
+		{
+			name:   "minor",
+			config: config,
+			policy: policy.Policy{Level: policy.Minor},
+			want:   "    rev: v1.1.0 # pinned",
+		},
+		{
+			name:   "freeze",
+			config: config,
+			policy: policy.Policy{Freeze: true},
+			want:   "    rev: " + commits["v2.0.0"] + "  # frozen: v2.0.0 # pinned",
+		},
+		{
+			name:   "freeze without a newer version",
+			config: strings.Replace(config, "v1.0.0", "v2.0.0", 1),
+			policy: policy.Policy{Freeze: true},
+			want:   "    rev: " + commits["v2.0.0"] + "  # frozen: v2.0.0 # pinned",
+		},
+		{
+			name:   "unfreeze",
+			config: strings.Replace(config, "v1.0.0 # pinned", commits["v1.0.0"]+"  # frozen: v1.0.0", 1),
+			want:   "    rev: v2.0.0",
+		},
+		{
+			name:   "up to date",
+			config: strings.Replace(config, "v1.0.0", "v2.0.0", 1),
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".pre-commit-config.yaml")
+
+			err := os.WriteFile(path, []byte(test.config), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			changes := parsePreCommit(path, test.policy)
+			if test.want == "" {
+				assert.Len(t, changes, 0, test.name)
+
+				return
+			}
+
+			if !assert.Len(t, changes, 1, test.name) {
+				return
+			}
+
+			assert.Equal(t, 3, changes[0].lineNumber, test.name)
+
+			changes[0].Apply()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.want, strings.Split(string(data), "\n")[2], test.name)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package changes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mhristof/bump/updater"
+	"gopkg.in/yaml.v3"
+)
+
+// Report is the machine readable form of a Change.
+type Report struct {
+	File         string `json:"file,omitempty" yaml:"file,omitempty"`
+	Line         int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Type         string `json:"type" yaml:"type"`
+	Kind         string `json:"kind" yaml:"kind"`
+	Name         string `json:"name,omitempty" yaml:"name,omitempty"`
+	Current      string `json:"current,omitempty" yaml:"current,omitempty"`
+	Proposed     string `json:"proposed,omitempty" yaml:"proposed,omitempty"`
+	From         string `json:"from" yaml:"from"`
+	To           string `json:"to" yaml:"to"`
+	ReleaseNotes string `json:"release_notes,omitempty" yaml:"release_notes,omitempty"`
+	Applied      bool   `json:"applied" yaml:"applied"`
+}
+
+// Report returns the machine readable form of the change. The release notes
+// link is the one Update found in the source repository of the change.
+// Digest changes report the digests as the current and proposed values, and
+// replacements, which have no versions, report none.
+func (c Change) Report() Report {
+	ret := Report{
+		File:         c.file,
+		Line:         c.lineNumber,
+		Type:         c.updater,
+		Kind:         c.kind.String(),
+		Name:         c.Module,
+		From:         c.line,
+		To:           c.NewLine,
+		ReleaseNotes: c.releaseNotes,
+		Applied:      c.applied,
+	}
+
+	if ret.Type == "" {
+		ret.Type = c.format.String()
+	}
+
+	if c.version != nil {
+		ret.Current = c.version.Original()
+	}
+
+	if c.newVersion != nil {
+		ret.Proposed = c.newVersion.Original()
+	}
+
+	if c.kind == Digest {
+		ret.Current = updater.Digest(c.line)
+		ret.Proposed = updater.Digest(c.NewLine)
+	}
+
+	return ret
+}
+
+// WriteReport writes the report of every change to w, as json or yaml.
+func (c Changes) WriteReport(w io.Writer, format string) error {
+	reports := make([]Report, 0, len(c))
+	for _, change := range c {
+		reports = append(reports, change.Report())
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+
+		return encoder.Encode(reports)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+
+		err := encoder.Encode(reports)
+		if err != nil {
+			return err
+		}
+
+		return encoder.Close()
+	}
+
+	return fmt.Errorf("unsupported report format %s", format)
+}
@@ -0,0 +1,156 @@
+package changes
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteReport(t *testing.T) {
+	changes := Changes{
+		{
+			file:       "main.tf",
+			lineNumber: 3,
+			format:     Terraform,
+			Module:     "vpc",
+			line:       "~> 4.0",
+			NewLine:    "~> 5.0",
+			version:    semver.MustParse("4.0.2"),
+			newVersion: semver.MustParse("5.1.2"),
+			applied:    true,
+		},
+		{
+			file:       "Dockerfile",
+			lineNumber: 1,
+			updater:    "dockerhub",
+			line:       "FROM prom/alertmanager:v0.25.0",
+			NewLine:    "FROM prom/alertmanager:v0.26.0",
+			version:    semver.MustParse("v0.25.0"),
+			newVersion: semver.MustParse("v0.26.0"),
+			Source:     "https://github.com/prometheus/alertmanager",
+			// Report does not look the link up again.
+			releaseNotes: "https://github.com/prometheus/alertmanager/compare/0.25.0...0.26.0",
+		},
+		{
+			file:       "Dockerfile",
+			lineNumber: 2,
+			updater:    "dockerhub",
+			line:       "FROM library/node:20@sha256:" + strings.Repeat("0", 64),
+			NewLine:    "FROM library/node:20@sha256:" + strings.Repeat("a", 64),
+			version:    semver.MustParse("20"),
+			newVersion: semver.MustParse("20"),
+			kind:       Digest,
+		},
+	}
+
+	cases := []struct {
+		name   string
+		format string
+		want   string
+		err    bool
+	}{
+		{
+			name:   "json",
+			format: "json",
+			want: heredoc.Doc(`
+				[
+				  {
+				    "file": "main.tf",
+				    "line": 3,
+				    "type": "terraform",
+				    "kind": "version",
+				    "name": "vpc",
+				    "current": "4.0.2",
+				    "proposed": "5.1.2",
+				    "from": "~> 4.0",
+				    "to": "~> 5.0",
+				    "applied": true
+				  },
+				  {
+				    "file": "Dockerfile",
+				    "line": 1,
+				    "type": "dockerhub",
+				    "kind": "version",
+				    "current": "v0.25.0",
+				    "proposed": "v0.26.0",
+				    "from": "FROM prom/alertmanager:v0.25.0",
+				    "to": "FROM prom/alertmanager:v0.26.0",
+				    "release_notes": "https://github.com/prometheus/alertmanager/compare/0.25.0...0.26.0",
+				    "applied": false
+				  },
+				  {
+				    "file": "Dockerfile",
+				    "line": 2,
+				    "type": "dockerhub",
+				    "kind": "digest",
+				    "current": "sha256:0000000000000000000000000000000000000000000000000000000000000000",
+				    "proposed": "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+				    "from": "FROM library/node:20@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+				    "to": "FROM library/node:20@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+				    "applied": false
+				  }
+				]
+			`),
+		},
+		{
+			name:   "yaml",
+			format: "yaml",
+			want: heredoc.Doc(`
+				- file: main.tf
+				  line: 3
+				  type: terraform
+				  kind: version
+				  name: vpc
+				  current: 4.0.2
+				  proposed: 5.1.2
+				  from: ~> 4.0
+				  to: ~> 5.0
+				  applied: true
+				- file: Dockerfile
+				  line: 1
+				  type: dockerhub
+				  kind: version
+				  current: v0.25.0
+				  proposed: v0.26.0
+				  from: FROM prom/alertmanager:v0.25.0
+				  to: FROM prom/alertmanager:v0.26.0
+				  release_notes: https://github.com/prometheus/alertmanager/compare/0.25.0...0.26.0
+				  applied: false
+				- file: Dockerfile
+				  line: 2
+				  type: dockerhub
+				  kind: digest
+				  current: sha256:0000000000000000000000000000000000000000000000000000000000000000
+				  proposed: sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+				  from: FROM library/node:20@sha256:0000000000000000000000000000000000000000000000000000000000000000
+				  to: FROM library/node:20@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+				  applied: false
+			`),
+		},
+		{
+			name:   "unsupported",
+			format: "xml",
+			err:    true,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := changes.WriteReport(&out, test.format)
+			if test.err {
+				assert.NotNil(t, err, test.name)
+
+				return
+			}
+
+			assert.Nil(t, err, test.name)
+			assert.Equal(t, test.want, out.String(), test.name)
+		})
+	}
+}
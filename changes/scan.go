@@ -0,0 +1,67 @@
+package changes
+
+import (
+	"os"
+	"strings"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+// scanSource offers every line of the files an updater scans to it.
+type scanSource struct {
+	updaters updater.Set
+}
+
+func (s scanSource) Handles(path string) bool {
+	return s.updaters.Scans(path)
+}
+
+func (s scanSource) Parse(path string, p policy.Policy) (Changes, coverage) {
+	changes, lines := parseScanned(path, s.updaters, p)
+
+	return changes, coverLines(lines)
+}
+
+// parseScanned returns a change for every line of the file at path that an
+// updater scanning the file has a newer version for, and the lines those
+// updaters handle. Lines like uses: actions/checkout@v4 are found here
+// because they have no x.y.z version for the lines to be picked by.
+func parseScanned(path string, updaters updater.Set, p policy.Policy) (Changes, map[int]struct{}) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.WithField("file", path).Error("Failed to read file")
+
+		return nil, nil
+	}
+
+	var ret Changes
+
+	lines := map[int]struct{}{}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		u := updaters.Match(path, line)
+		if u == nil {
+			continue
+		}
+
+		if scanner, ok := u.Updater.(updater.Scanner); !ok || !scanner.Scan(path) {
+			continue
+		}
+
+		lines[i+1] = struct{}{}
+
+		change := &Change{
+			line:       line,
+			lineNumber: i + 1,
+			file:       path,
+		}
+
+		if change.update(u, p) {
+			ret = append(ret, change)
+		}
+	}
+
+	return ret, lines
+}
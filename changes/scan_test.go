@@ -0,0 +1,64 @@
+package changes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/policy"
+	"github.com/mhristof/bump/updater"
+	"github.com/stretchr/testify/assert"
+)
+
+// majorUpdater offers v4 for every action on v3 of the workflows.
+type majorUpdater struct{}
+
+func (majorUpdater) Scan(file string) bool {
+	return strings.Contains(file, ".github/workflows/")
+}
+
+func (majorUpdater) Match(file, line string) bool {
+	return strings.Contains(line, "uses: actions/")
+}
+
+func (majorUpdater) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	return semver.MustParse("v3"), []*semver.Version{semver.MustParse("v4")}, nil
+}
+
+func (majorUpdater) Rewrite(line string, current, next *semver.Version) string {
+	return strings.ReplaceAll(line, "@"+current.Original(), "@"+next.Original())
+}
+
+func TestParseScanned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".github", "workflows", "ci.yml")
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := `on: push
+jobs:
+  test:
+    steps:
+      - uses: actions/checkout@v3
+      - uses: ./.github/actions/local
+      - run: make test
+`
+
+	err = os.WriteFile(path, []byte(data), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, lines := parseScanned(path, updater.Set{{Name: "actions", Updater: majorUpdater{}}}, policy.Policy{})
+
+	assert.Equal(t, map[int]struct{}{5: {}}, lines)
+
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, 5, changes[0].lineNumber)
+		assert.Equal(t, "      - uses: actions/checkout@v4", changes[0].NewLine)
+	}
+}
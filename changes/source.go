@@ -0,0 +1,88 @@
+package changes
+
+import (
+	"fmt"
+
+	"github.com/mhristof/bump/policy"
+	log "github.com/sirupsen/logrus"
+)
+
+// fileSource finds the changes of whole files, like the requirements of a
+// go.mod or the images of a manifest, where the lines alone do not say what
+// to update.
+type fileSource interface {
+	// Handles reports whether the source parses the file at path.
+	Handles(path string) bool
+	// Parse returns the changes of the file at path and the lines it
+	// handled, which are not offered to the line updaters.
+	Parse(path string, p policy.Policy) (Changes, coverage)
+}
+
+// coverage is the set of 1-based lines of a file a source handled. all
+// covers every line of the file.
+type coverage struct {
+	all   bool
+	lines map[int]struct{}
+}
+
+func coverAll() coverage {
+	return coverage{all: true}
+}
+
+func coverLines(lines map[int]struct{}) coverage {
+	return coverage{lines: lines}
+}
+
+func (c coverage) has(line int) bool {
+	if c.all {
+		return true
+	}
+
+	_, ok := c.lines[line]
+
+	return ok
+}
+
+// parseFile runs every source that handles the file at path, in order, and
+// returns their changes and the lines they handled. A source that handles
+// every line stops the ones after it.
+func parseFile(path string, sources []fileSource, p policy.Policy) (Changes, []coverage) {
+	var (
+		ret     Changes
+		covered []coverage
+	)
+
+	for _, source := range sources {
+		if !source.Handles(path) {
+			continue
+		}
+
+		changes, lines := source.Parse(path, p)
+
+		log.WithFields(log.Fields{
+			"file":    path,
+			"source":  fmt.Sprintf("%T", source),
+			"changes": changes,
+		}).Debug("Found file changes")
+
+		ret = append(ret, changes...)
+		covered = append(covered, lines)
+
+		if lines.all {
+			break
+		}
+	}
+
+	return ret, covered
+}
+
+// handled reports whether any of the coverages has line.
+func handled(covered []coverage, line int) bool {
+	for _, c := range covered {
+		if c.has(line) {
+			return true
+		}
+	}
+
+	return false
+}
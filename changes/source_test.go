@@ -0,0 +1,86 @@
+package changes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+// lineSource handles the YAML files and the given lines of them.
+type lineSource struct {
+	module string
+	lines  map[int]struct{}
+	all    bool
+}
+
+func (s lineSource) Handles(path string) bool {
+	return strings.HasSuffix(path, ".yaml")
+}
+
+func (s lineSource) Parse(path string, p policy.Policy) (Changes, coverage) {
+	changes := Changes{{Module: s.module, file: path}}
+
+	if s.all {
+		return changes, coverAll()
+	}
+
+	return changes, coverLines(s.lines)
+}
+
+func TestParseFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		path    string
+		sources []fileSource
+		modules []string
+		handled map[int]bool
+	}{
+		{
+			name: "sources add up",
+			path: "values.yaml",
+			sources: []fileSource{
+				lineSource{module: "chart", lines: map[int]struct{}{3: {}}},
+				lineSource{module: "image", lines: map[int]struct{}{7: {}}},
+			},
+			modules: []string{"chart", "image"},
+			handled: map[int]bool{3: true, 5: false, 7: true},
+		},
+		{
+			name: "whole file stops the rest",
+			path: "values.yaml",
+			sources: []fileSource{
+				lineSource{module: "config", all: true},
+				lineSource{module: "image", lines: map[int]struct{}{7: {}}},
+			},
+			modules: []string{"config"},
+			handled: map[int]bool{1: true, 7: true},
+		},
+		{
+			name: "file not handled",
+			path: "main.tf",
+			sources: []fileSource{
+				lineSource{module: "config", all: true},
+			},
+			handled: map[int]bool{1: false},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			changes, covered := parseFile(test.path, test.sources, policy.Policy{})
+
+			var modules []string
+			for _, change := range changes {
+				modules = append(modules, change.Module)
+			}
+
+			assert.Equal(t, test.modules, modules, test.name)
+
+			for line, want := range test.handled {
+				assert.Equal(t, want, handled(covered, line), test.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show the changes as a unified diff without modifying any file",
+	Run: func(cmd *cobra.Command, args []string) {
+		run(args, "diff")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
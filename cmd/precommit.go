@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/mhristof/bump/precommit"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var precommitCmd = &cobra.Command{
+	Use:   "pre-commit [path]",
+	Short: "Update the repos of a pre-commit configuration",
+	Long: "Update the rev of every repo of the .pre-commit-config.yaml in path, " +
+		"which defaults to the current directory. path can also be the configuration itself. " +
+		"Tags are resolved through the GitHub API or git ls-remote, and --freeze pins every rev to a commit.",
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			path = filepath.Join(path, precommit.ConfigFile)
+		}
+
+		run([]string{path}, viper.GetString("output"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(precommitCmd)
+}
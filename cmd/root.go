@@ -6,6 +6,7 @@ import (
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/mhristof/bump/changes"
+	"github.com/mhristof/bump/policy"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,31 +22,105 @@ var rootCmd = &cobra.Command{
 	Long: heredoc.Doc(`
 		Bump versions for different stuff.
 
-		You can pass a string or a file
+		You can pass a string, a file or a directory. Directories are scanned
+		recursively, skipping .git, .terraform, vendor and anything in .gitignore.
 	`),
 	Version: version,
+	// Paths are not subcommands, even if the root command has some.
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		ch := changes.New(args)
+		run(args, viper.GetString("output"))
+	},
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+	},
+}
 
-		ch.Update(viper.GetInt("max-procs"))
+func run(args []string, output string) {
+	ch := changes.New(args, changes.Filter{
+		Include: viper.GetStringSlice("include"),
+		Exclude: viper.GetStringSlice("exclude"),
+	})
 
-		log.WithField("len", len(ch)).Debug("number of changes")
+	p := loadPolicy()
 
-		for _, c := range ch {
-			log.WithField("change", c).Debug("Change")
+	for _, arg := range args {
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			p.Roots = append(p.Roots, arg)
+		}
+	}
 
-			if viper.GetBool("dryrun") {
-				log.WithField("change", c).Info("Change")
+	ch.Update(viper.GetInt("max-procs"), p)
+
+	log.WithField("len", len(ch)).Debug("number of changes")
+
+	switch output {
+	case "log", "json", "yaml":
+	case "diff":
+		err := ch.Diff(os.Stdout, isTerminal(os.Stdout))
+		if err != nil {
+			log.WithField("error", err).Fatal("cannot render diff")
+		}
 
-				continue
+		return
+	default:
+		log.WithField("output", output).Fatal("unsupported output")
+	}
+
+	for _, c := range ch {
+		log.WithField("change", c).Debug("Change")
+
+		if viper.GetBool("dryrun") {
+			if output == "log" {
+				log.WithField("change", c).Info("Change")
 			}
 
-			c.Apply()
+			continue
 		}
-	},
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		Verbose(cmd)
-	},
+
+		c.Apply()
+	}
+
+	if output == "log" {
+		return
+	}
+
+	err := ch.WriteReport(os.Stdout, output)
+	if err != nil {
+		log.WithField("error", err).Fatal("cannot write report")
+	}
+}
+
+func loadPolicy() policy.Policy {
+	var overrides []policy.Override
+
+	err := viper.UnmarshalKey("overrides", &overrides)
+	if err != nil {
+		log.WithField("error", err).Fatal("cannot parse overrides")
+	}
+
+	p, err := policy.New(viper.GetString("level"), viper.GetString("prerelease"), overrides)
+	if err != nil {
+		log.WithField("error", err).Fatal("invalid policy")
+	}
+
+	p.Freeze = viper.GetBool("freeze")
+	p.Pin = viper.GetBool("pin-digest")
+
+	return p
+}
+
+func isTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
 // Verbose Increase verbosity.
@@ -73,9 +148,23 @@ func init() {
 	rootCmd.PersistentFlags().CountP("verbose", "v", "Increase verbosity")
 	rootCmd.PersistentFlags().BoolP("dryrun", "n", false, "Dry run")
 	rootCmd.PersistentFlags().IntP("max-procs", "P", 10, "Number of max threads to run when available")
+	rootCmd.PersistentFlags().StringP("level", "l", "major", "Largest update allowed, one of patch, minor or major")
+	rootCmd.PersistentFlags().String("prerelease", "same-track", "Pre-releases to pick, one of never, same-track or always. same-track only moves a pre-release to a later pre-release of the same version or to its release")
+	rootCmd.PersistentFlags().Bool("freeze", false, "Pin pre-commit revs to the commit of the version, with the version in a comment")
+	rootCmd.PersistentFlags().Bool("pin-digest", false, "Pin container images as name:tag@sha256:digest. Images that already have a digest always get the current digest of their tag")
+	rootCmd.PersistentFlags().StringSlice("include", []string{}, "Only scan files matching these globs inside directories")
+	rootCmd.PersistentFlags().StringSlice("exclude", []string{}, "Skip files and directories matching these globs inside directories")
+	rootCmd.PersistentFlags().StringP("output", "o", "log", "Output format, one of log, diff, json or yaml. diff does not modify any file")
 
 	viper.BindPFlag("max-procs", rootCmd.PersistentFlags().Lookup("max-procs"))
 	viper.BindPFlag("dryrun", rootCmd.PersistentFlags().Lookup("dryrun"))
+	viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	viper.BindPFlag("level", rootCmd.PersistentFlags().Lookup("level"))
+	viper.BindPFlag("prerelease", rootCmd.PersistentFlags().Lookup("prerelease"))
+	viper.BindPFlag("freeze", rootCmd.PersistentFlags().Lookup("freeze"))
+	viper.BindPFlag("pin-digest", rootCmd.PersistentFlags().Lookup("pin-digest"))
+	viper.BindPFlag("include", rootCmd.PersistentFlags().Lookup("include"))
+	viper.BindPFlag("exclude", rootCmd.PersistentFlags().Lookup("exclude"))
 
 	viper.SetConfigName("bump") // name of config file (without extension)
 	viper.SetConfigType("yaml") // REQUIRED if the config file does not have the extension in the name
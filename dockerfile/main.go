@@ -0,0 +1,410 @@
+package dockerfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IsDockerfile reports whether path looks like a Dockerfile, such as
+// Dockerfile, Dockerfile.dev, build.Dockerfile or Containerfile.
+func IsDockerfile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+
+	return name == "dockerfile" || name == "containerfile" ||
+		strings.HasPrefix(name, "dockerfile.") || strings.HasSuffix(name, ".dockerfile")
+}
+
+// Image is an image of a FROM instruction with its variables expanded.
+type Image struct {
+	// Registry is the host of the image, empty for Docker Hub.
+	Registry string
+	// Repository is the path of the image, such as library/alpine.
+	Repository string
+	Tag        string
+	Digest     string
+	// Stage is the name given with AS.
+	Stage string
+	// Line is the 1-based line the tag is written in, which is the line of
+	// an ARG when the tag comes from a variable.
+	Line int
+	// From is the 1-based line of the FROM instruction.
+	From int
+
+	// text is the line Line, and the tag bytes [start, end) are written in
+	// it at offset.
+	text   string
+	from   string
+	offset int
+	start  int
+	end    int
+}
+
+// Reference returns the image as registry/repository:tag, without the
+// registry for Docker Hub images.
+func (i Image) Reference() string {
+	ret := i.Repository
+	if i.Registry != "" {
+		ret = i.Registry + "/" + ret
+	}
+
+	if i.Tag != "" {
+		ret += ":" + i.Tag
+	}
+
+	return ret
+}
+
+// Text returns the line the tag is written in.
+func (i Image) Text() string {
+	return i.text
+}
+
+// FromText returns the line of the FROM instruction.
+func (i Image) FromText() string {
+	return i.from
+}
+
+// Rewrite returns the line the tag is written in with the tag set to tag.
+// It fails when the part of the tag that changes is not written in that
+// line, for example when it is split between a variable and the FROM line.
+func (i Image) Rewrite(tag string) (string, error) {
+	prefix := commonPrefix(i.Tag, tag)
+	suffix := commonSuffix(i.Tag[prefix:], tag[prefix:])
+
+	if i.offset < 0 || prefix < i.start || len(i.Tag)-suffix > i.end {
+		return "", fmt.Errorf("cannot rewrite tag %s to %s in %q", i.Tag, tag, i.text)
+	}
+
+	written := tag[i.start : len(tag)-(len(i.Tag)-i.end)]
+
+	return i.text[:i.offset] + written + i.text[i.offset+i.end-i.start:], nil
+}
+
+// SetDigest returns the FROM instruction in line with the image pinned to
+// digest. An existing digest is replaced, unless it comes from a variable.
+func SetDigest(line, digest string) (string, error) {
+	matches := instructionRegex.FindStringSubmatchIndex(line)
+	if matches == nil || !strings.EqualFold(line[matches[2]:matches[3]], "from") {
+		return "", fmt.Errorf("cannot find FROM instruction in %q", line)
+	}
+
+	rest := line[matches[4]:matches[5]]
+
+	fields := fieldIndexes(rest)
+	for len(fields) > 0 && strings.HasPrefix(rest[fields[0][0]:], "--") {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 {
+		return "", fmt.Errorf("cannot find image in %q", line)
+	}
+
+	start, end := matches[4]+fields[0][0], matches[4]+fields[0][1]
+	name := line[start:end]
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		if strings.Contains(name[i:], "$") {
+			return "", fmt.Errorf("cannot rewrite digest from a variable in %q", line)
+		}
+
+		end = start + i
+	}
+
+	return line[:end] + "@" + digest + line[start+len(name):], nil
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	return i
+}
+
+func commonSuffix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[len(a)-1-i] == b[len(b)-1-i] {
+		i++
+	}
+
+	return i
+}
+
+type arg struct {
+	value  string
+	line   int
+	text   string
+	offset int
+}
+
+var (
+	instructionRegex = regexp.MustCompile(`^\s*(?i:(from|arg))\s+(.*)$`)
+	variableRegex    = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
+)
+
+// Parse returns the images of the FROM instructions of data that have a tag
+// or a digest. References to earlier stages and scratch are skipped. Tags
+// may come from the ARG instructions before the first FROM, written as
+// $NAME or ${NAME}.
+func Parse(data []byte) []Image {
+	var ret []Image
+
+	args := map[string]arg{}
+	stages := map[string]struct{}{}
+	global := true
+
+	for n, text := range strings.Split(string(data), "\n") {
+		matches := instructionRegex.FindStringSubmatchIndex(text)
+		if matches == nil {
+			continue
+		}
+
+		instruction := strings.ToLower(text[matches[2]:matches[3]])
+		rest := text[matches[4]:matches[5]]
+		restOffset := matches[4]
+
+		if instruction == "arg" {
+			if global {
+				for name, a := range parseArgs(rest, restOffset) {
+					a.line = n + 1
+					a.text = text
+					args[name] = a
+				}
+			}
+
+			continue
+		}
+
+		global = false
+
+		image, ok := parseFrom(n+1, text, rest, restOffset, args)
+		if !ok {
+			continue
+		}
+
+		_, stage := stages[strings.ToLower(image.Repository)]
+
+		if image.Stage != "" {
+			stages["library/"+strings.ToLower(image.Stage)] = struct{}{}
+		}
+
+		if stage || image.Repository == "library/scratch" || (image.Tag == "" && image.Digest == "") {
+			continue
+		}
+
+		ret = append(ret, image)
+	}
+
+	return ret
+}
+
+// segment is a part of an expanded reference and where it is written.
+type segment struct {
+	start, end int
+	line       int
+	text       string
+	offset     int
+}
+
+// parseFrom parses the FROM instruction in text, found in line n. rest is
+// the text after FROM, which starts at offset.
+func parseFrom(n int, text, rest string, offset int, args map[string]arg) (Image, bool) {
+	ret := Image{From: n, from: text}
+
+	fields := fieldIndexes(rest)
+	for len(fields) > 0 && strings.HasPrefix(rest[fields[0][0]:], "--") {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 {
+		return ret, false
+	}
+
+	if len(fields) >= 3 && strings.EqualFold(rest[fields[1][0]:fields[1][1]], "as") {
+		ret.Stage = rest[fields[2][0]:fields[2][1]]
+	}
+
+	raw := rest[fields[0][0]:fields[0][1]]
+	rawOffset := offset + fields[0][0]
+
+	var (
+		expanded string
+		segments []segment
+		last     int
+	)
+
+	literal := func(from, to int) {
+		if from == to {
+			return
+		}
+
+		segments = append(segments, segment{
+			start:  len(expanded),
+			end:    len(expanded) + to - from,
+			line:   n,
+			text:   text,
+			offset: rawOffset + from,
+		})
+		expanded += raw[from:to]
+	}
+
+	for _, match := range variableRegex.FindAllStringSubmatchIndex(raw, -1) {
+		var name string
+		if match[2] >= 0 {
+			name = raw[match[2]:match[3]]
+		} else {
+			name = raw[match[4]:match[5]]
+		}
+
+		a, ok := args[name]
+		if !ok {
+			return ret, false
+		}
+
+		literal(last, match[0])
+
+		segments = append(segments, segment{
+			start:  len(expanded),
+			end:    len(expanded) + len(a.value),
+			line:   a.line,
+			text:   a.text,
+			offset: a.offset,
+		})
+		expanded += a.value
+		last = match[1]
+	}
+
+	literal(last, len(raw))
+
+	name, tagStart := expanded, -1
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		ret.Digest = name[i+1:]
+		name = name[:i]
+	}
+
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ret.Tag = name[i+1:]
+		tagStart = i + 1
+		name = name[:i]
+	}
+
+	ret.Registry, ret.Repository = SplitName(name)
+
+	if ret.Tag == "" {
+		ret.Line = n
+		ret.text = text
+
+		return ret, true
+	}
+
+	tagEnd := tagStart + len(ret.Tag)
+
+	for _, seg := range segments {
+		from, to := seg.start, seg.end
+		if from < tagStart {
+			from = tagStart
+		}
+
+		if to > tagEnd {
+			to = tagEnd
+		}
+
+		if from >= to || !strings.ContainsAny(expanded[from:to], "0123456789") {
+			continue
+		}
+
+		ret.Line = seg.line
+		ret.text = seg.text
+		ret.offset = seg.offset + from - seg.start
+		ret.start = from - tagStart
+		ret.end = to - tagStart
+
+		return ret, true
+	}
+
+	ret.Line = n
+	ret.text = text
+	ret.offset = -1
+
+	return ret, true
+}
+
+// SplitName splits name into its registry and repository. Docker Hub images
+// have no registry and official images live under library/.
+func SplitName(name string) (string, string) {
+	registry := ""
+
+	if i := strings.Index(name, "/"); i >= 0 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			registry, name = first, name[i+1:]
+		}
+	}
+
+	switch registry {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		registry = ""
+	}
+
+	if registry == "" && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+
+	return registry, name
+}
+
+// parseArgs parses the NAME=value pairs of an ARG instruction. Quotes around
+// the value are not part of it.
+func parseArgs(rest string, offset int) map[string]arg {
+	ret := map[string]arg{}
+
+	for _, field := range fieldIndexes(rest) {
+		token := rest[field[0]:field[1]]
+
+		i := strings.Index(token, "=")
+		if i < 0 {
+			continue
+		}
+
+		value := token[i+1:]
+		start := offset + field[0] + i + 1
+
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+			start++
+		}
+
+		ret[token[:i]] = arg{value: value, offset: start}
+	}
+
+	return ret
+}
+
+// fieldIndexes returns the start and end of every whitespace separated field
+// of s.
+func fieldIndexes(s string) [][2]int {
+	var ret [][2]int
+
+	start := -1
+
+	for i := 0; i <= len(s); i++ {
+		if i == len(s) || s[i] == ' ' || s[i] == '\t' {
+			if start >= 0 {
+				ret = append(ret, [2]int{start, i})
+				start = -1
+			}
+
+			continue
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,144 @@
+package dockerfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDockerfile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"Dockerfile":              true,
+		"build/Dockerfile.dev":    true,
+		"images/app.Dockerfile":   true,
+		"Containerfile":           true,
+		"dockerfile":              true,
+		"Dockerfile-generator.go": false,
+		"main.tf":                 false,
+	} {
+		assert.Equal(t, want, IsDockerfile(path), path)
+	}
+}
+
+func TestParse(t *testing.T) {
+	data := `# syntax=docker/dockerfile:1
+ARG GO_VERSION=1.20.5
+ARG BASE="alpine:3.18"
+ARG DEBIAN=bookworm
+FROM golang:${GO_VERSION}-alpine AS build
+ARG IGNORED=1.0.0
+FROM --platform=linux/amd64 python:3.11-slim as python
+FROM build AS test
+FROM ${BASE}
+FROM ghcr.io/org/app:v1.2.3
+FROM 123456789012.dkr.ecr.eu-west-1.amazonaws.com/team/app:2.0.0
+FROM docker.io/bitnami/redis:7.0.11
+FROM debian:$DEBIAN
+FROM scratch
+from ubuntu
+FROM nginx:1.25@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31
+FROM ${UNKNOWN}:1.0.0
+`
+
+	images := Parse([]byte(data))
+
+	type want struct {
+		reference string
+		stage     string
+		line      int
+		digest    string
+	}
+
+	got := make([]want, len(images))
+	for i, image := range images {
+		got[i] = want{image.Reference(), image.Stage, image.Line, image.Digest}
+	}
+
+	assert.Equal(t, []want{
+		{reference: "library/golang:1.20.5-alpine", stage: "build", line: 2},
+		{reference: "library/python:3.11-slim", stage: "python", line: 7},
+		{reference: "library/alpine:3.18", line: 3},
+		{reference: "ghcr.io/org/app:v1.2.3", line: 10},
+		{reference: "123456789012.dkr.ecr.eu-west-1.amazonaws.com/team/app:2.0.0", line: 11},
+		{reference: "bitnami/redis:7.0.11", line: 12},
+		{reference: "library/debian:bookworm", line: 13},
+		{reference: "library/nginx:1.25", line: 16, digest: "sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"},
+	}, got)
+}
+
+func TestRewrite(t *testing.T) {
+	data := `ARG GO_VERSION=1.20.5
+ARG BASE="alpine:3.18"
+ARG SUFFIX=-alpine3.18
+FROM golang:${GO_VERSION}-alpine AS build
+FROM ${BASE}
+FROM python:3.11-slim AS python
+FROM node:18${SUFFIX}
+`
+
+	images := Parse([]byte(data))
+	if !assert.Len(t, images, 4) {
+		return
+	}
+
+	cases := []struct {
+		name  string
+		image Image
+		tag   string
+		want  string
+		err   bool
+	}{
+		{name: "in an ARG", image: images[0], tag: "1.21.0-alpine", want: "ARG GO_VERSION=1.21.0"},
+		{name: "quoted ARG with the image", image: images[1], tag: "3.19", want: `ARG BASE="alpine:3.19"`},
+		{name: "in the FROM line", image: images[2], tag: "3.12-slim", want: "FROM python:3.12-slim AS python"},
+		{name: "in the FROM line with an ARG suffix", image: images[3], tag: "20-alpine3.18", want: "FROM node:20${SUFFIX}"},
+		{name: "across an ARG", image: images[3], tag: "20-alpine3.19", err: true},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.image.Rewrite(test.tag)
+			if test.err {
+				assert.NotNil(t, err, test.name)
+
+				return
+			}
+
+			assert.Nil(t, err, test.name)
+			assert.Equal(t, test.want, got, test.name)
+		})
+	}
+}
+
+func TestSetDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	cases := []struct {
+		name string
+		line string
+		want string
+		err  bool
+	}{
+		{name: "adds the digest", line: "FROM alpine:3.19", want: "FROM alpine:3.19@" + digest},
+		{name: "with platform and stage", line: "FROM --platform=$BUILDPLATFORM golang:1.21 AS build", want: "FROM --platform=$BUILDPLATFORM golang:1.21@" + digest + " AS build"},
+		{name: "replaces the digest", line: "from nginx:1.25@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31", want: "from nginx:1.25@" + digest},
+		{name: "tag from a variable", line: "FROM golang:${GO_VERSION}", want: "FROM golang:${GO_VERSION}@" + digest},
+		{name: "digest from a variable", line: "FROM golang:1.21@${DIGEST}", err: true},
+		{name: "not a FROM", line: "ARG GO_VERSION=1.21", err: true},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := SetDigest(test.line, digest)
+			if test.err {
+				assert.NotNil(t, err, test.name)
+
+				return
+			}
+
+			assert.Nil(t, err, test.name)
+			assert.Equal(t, test.want, got, test.name)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package gomod
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// IsGoMod reports whether path is a go.mod file.
+func IsGoMod(path string) bool {
+	return filepath.Base(path) == "go.mod"
+}
+
+// Require is a module required by a go.mod file.
+type Require struct {
+	Path    string
+	Version string
+	// Line is the 1-based line of the requirement.
+	Line int
+}
+
+// Parse returns the direct requirements of the go.mod file in data.
+// Indirect requirements are left to go mod tidy and replaced modules are
+// skipped, since the version in the require is not the one in use.
+func Parse(data []byte) ([]Require, error) {
+	f, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse go.mod: %w", err)
+	}
+
+	replaced := map[string]struct{}{}
+	for _, r := range f.Replace {
+		replaced[r.Old.Path] = struct{}{}
+	}
+
+	var ret []Require
+
+	for _, r := range f.Require {
+		if _, ok := replaced[r.Mod.Path]; ok || r.Indirect {
+			continue
+		}
+
+		ret = append(ret, Require{
+			Path:    r.Mod.Path,
+			Version: r.Mod.Version,
+			Line:    r.Syntax.Start.Line,
+		})
+	}
+
+	return ret, nil
+}
+
+// Candidates returns the versions of versions that path at current can move
+// to. The major version of a module is part of its path from v2 on, so
+// other majors only come as +incompatible versions of modules without a
+// go.mod, and those are kept apart from the compatible ones.
+func Candidates(path, current string, versions []string) []string {
+	var ret []string
+
+	_, pathMajor, _ := module.SplitPathVersion(path)
+	incompatible := strings.HasSuffix(current, "+incompatible")
+
+	for _, v := range versions {
+		if !semver.IsValid(v) || strings.HasSuffix(v, "+incompatible") != incompatible {
+			continue
+		}
+
+		if module.CheckPathMajor(v, pathMajor) != nil {
+			continue
+		}
+
+		ret = append(ret, v)
+	}
+
+	return ret
+}
+
+// SetVersion returns data with the requirement of path set from from to to.
+func SetVersion(data []byte, path, from, to string) ([]byte, error) {
+	f, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse go.mod: %w", err)
+	}
+
+	found := false
+
+	for _, r := range f.Require {
+		if r.Mod.Path != path {
+			continue
+		}
+
+		if r.Mod.Version != from {
+			return nil, fmt.Errorf("%s is at %s, expected %s", path, r.Mod.Version, from)
+		}
+
+		found = true
+	}
+
+	if !found {
+		return nil, fmt.Errorf("cannot find requirement of %s", path)
+	}
+
+	err = f.AddRequire(path, to)
+	if err != nil {
+		return nil, fmt.Errorf("cannot set %s to %s: %w", path, to, err)
+	}
+
+	ret, err := f.Format()
+	if err != nil {
+		return nil, fmt.Errorf("cannot format go.mod: %w", err)
+	}
+
+	return ret, nil
+}
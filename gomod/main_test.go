@@ -0,0 +1,106 @@
+package gomod
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+var sample = heredoc.Doc(`
+	module example.com/app
+
+	go 1.19
+
+	require (
+		github.com/pkg/errors v0.9.1 // pinned for now
+		github.com/google/go-github/v50 v50.2.0
+		github.com/docker/docker v20.10.24+incompatible
+		golang.org/x/sys v0.9.0 // indirect
+		example.com/fork v1.0.0
+	)
+
+	require gopkg.in/yaml.v3 v3.0.1
+
+	replace example.com/fork => ../fork
+`)
+
+func TestParse(t *testing.T) {
+	requires, err := Parse([]byte(sample))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Require{
+		{Path: "github.com/pkg/errors", Version: "v0.9.1", Line: 6},
+		{Path: "github.com/google/go-github/v50", Version: "v50.2.0", Line: 7},
+		{Path: "github.com/docker/docker", Version: "v20.10.24+incompatible", Line: 8},
+		{Path: "gopkg.in/yaml.v3", Version: "v3.0.1", Line: 13},
+	}, requires)
+}
+
+func TestCandidates(t *testing.T) {
+	cases := []struct {
+		name     string
+		path     string
+		current  string
+		versions []string
+		want     []string
+	}{
+		{
+			name:     "major version suffix",
+			path:     "github.com/google/go-github/v50",
+			current:  "v50.2.0",
+			versions: []string{"v50.1.0", "v50.2.0", "v51.0.0", "invalid"},
+			want:     []string{"v50.1.0", "v50.2.0"},
+		},
+		{
+			name:     "compatible module stays below v2",
+			path:     "github.com/pkg/errors",
+			current:  "v0.9.1",
+			versions: []string{"v0.9.1", "v1.0.0", "v2.0.0+incompatible"},
+			want:     []string{"v0.9.1", "v1.0.0"},
+		},
+		{
+			name:     "incompatible module",
+			path:     "github.com/docker/docker",
+			current:  "v20.10.24+incompatible",
+			versions: []string{"v1.13.1", "v20.10.24+incompatible", "v24.0.5+incompatible"},
+			want:     []string{"v20.10.24+incompatible", "v24.0.5+incompatible"},
+		},
+		{
+			name:     "gopkg.in",
+			path:     "gopkg.in/yaml.v3",
+			current:  "v3.0.0",
+			versions: []string{"v2.4.0", "v3.0.1"},
+			want:     []string{"v3.0.1"},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Candidates(test.path, test.current, test.versions), test.name)
+		})
+	}
+}
+
+func TestSetVersion(t *testing.T) {
+	got, err := SetVersion([]byte(sample), "github.com/pkg/errors", "v0.9.1", "v1.0.0")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Contains(t, string(got), "\tgithub.com/pkg/errors v1.0.0 // pinned for now\n")
+	assert.Contains(t, string(got), "replace example.com/fork => ../fork\n")
+
+	got, err = SetVersion([]byte(sample), "gopkg.in/yaml.v3", "v3.0.1", "v3.0.2")
+	if assert.Nil(t, err) {
+		assert.Contains(t, string(got), "require gopkg.in/yaml.v3 v3.0.2\n")
+	}
+
+	_, err = SetVersion([]byte(sample), "github.com/pkg/errors", "v0.9.0", "v1.0.0")
+	assert.NotNil(t, err)
+
+	_, err = SetVersion([]byte(sample), "example.com/missing", "v1.0.0", "v1.1.0")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,200 @@
+package gomod
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/mod/module"
+)
+
+const defaultProxy = "https://proxy.golang.org,direct"
+
+// errNotFound is returned by a proxy that does not serve a module, which
+// lets the next proxy of a comma separated list answer.
+var errNotFound = errors.New("not found")
+
+// Proxy resolves module versions through the GOPROXY protocol. Proxies can
+// be http(s) URLs or file:// URLs of a directory laid out like a proxy.
+// direct and off are skipped since versions are never fetched from the
+// version control systems.
+type Proxy struct {
+	client  *http.Client
+	proxies []proxy
+	private string
+}
+
+type proxy struct {
+	url string
+	// fallback is set when the proxy is followed by |, so any error moves
+	// on to the next proxy and not only a missing module.
+	fallback bool
+}
+
+// NewProxy returns a client for goproxy, a GOPROXY list. Modules that match
+// the GOPRIVATE or GONOPROXY patterns of private are never looked up.
+func NewProxy(goproxy, private string) *Proxy {
+	ret := &Proxy{
+		client:  http.DefaultClient,
+		private: private,
+	}
+
+	for goproxy != "" {
+		var (
+			entry    string
+			fallback bool
+		)
+
+		if i := strings.IndexAny(goproxy, ",|"); i >= 0 {
+			entry, fallback, goproxy = goproxy[:i], goproxy[i] == '|', goproxy[i+1:]
+		} else {
+			entry, goproxy = goproxy, ""
+		}
+
+		entry = strings.TrimSpace(entry)
+		if entry == "" || entry == "direct" || entry == "off" {
+			continue
+		}
+
+		ret.proxies = append(ret.proxies, proxy{
+			url:      strings.TrimSuffix(entry, "/"),
+			fallback: fallback,
+		})
+	}
+
+	return ret
+}
+
+// Environment returns a client for the GOPROXY, GONOPROXY and GOPRIVATE of
+// the environment.
+func Environment() *Proxy {
+	goproxy := os.Getenv("GOPROXY")
+	if goproxy == "" {
+		goproxy = defaultProxy
+	}
+
+	private := os.Getenv("GONOPROXY")
+	if private == "" {
+		private = os.Getenv("GOPRIVATE")
+	}
+
+	return NewProxy(goproxy, private)
+}
+
+// Versions returns the tagged versions of the module path. Modules without
+// tags return the version of @latest, which is a pseudo-version.
+func (p *Proxy) Versions(path string) ([]string, error) {
+	if module.MatchPrefixPatterns(p.private, path) {
+		return nil, fmt.Errorf("module %s is private", path)
+	}
+
+	escaped, err := module.EscapePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot escape module path %s: %w", path, err)
+	}
+
+	data, err := p.get(escaped + "/@v/list")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := strings.Fields(string(data))
+	if len(versions) > 0 {
+		log.WithFields(log.Fields{
+			"module": path,
+			"len":    len(versions),
+		}).Debug("found module versions")
+
+		return versions, nil
+	}
+
+	data, err = p.get(escaped + "/@latest")
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Version string
+	}
+
+	err = json.Unmarshal(data, &info)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode latest version of %s: %w", path, err)
+	}
+
+	if info.Version == "" {
+		return nil, nil
+	}
+
+	return []string{info.Version}, nil
+}
+
+// get returns the file name of the first proxy that serves it.
+func (p *Proxy) get(name string) ([]byte, error) {
+	err := fmt.Errorf("no proxy to get %s from", name)
+
+	for _, proxy := range p.proxies {
+		var data []byte
+
+		data, err = proxy.get(p.client, name)
+		if err == nil {
+			return data, nil
+		}
+
+		log.WithFields(log.Fields{
+			"proxy": proxy.url,
+			"name":  name,
+			"error": err,
+		}).Debug("cannot get from proxy")
+
+		if !proxy.fallback && !errors.Is(err, errNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
+
+func (p proxy) get(client *http.Client, name string) ([]byte, error) {
+	if strings.HasPrefix(p.url, "file://") {
+		u, err := url.Parse(p.url)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse proxy %s: %w", p.url, err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(filepath.FromSlash(u.Path), filepath.FromSlash(name)))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %w", name, errNotFound)
+		}
+
+		return data, err
+	}
+
+	resp, err := client.Get(p.url + "/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
+		return nil, fmt.Errorf("%s: %w", name, errNotFound)
+	default:
+		return nil, fmt.Errorf("cannot get %s from %s: %s", name, p.url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", name, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,95 @@
+package gomod
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fileProxy writes files, named like the paths of the GOPROXY protocol, to
+// a directory and returns its file:// URL.
+func fileProxy(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(data), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return "file://" + filepath.ToSlash(dir)
+}
+
+func TestProxy(t *testing.T) {
+	files := fileProxy(t, map[string]string{
+		"github.com/!burnt!sushi/toml/@v/list": "v1.2.0\nv1.3.2\n",
+		"example.com/untagged/@v/list":         "",
+		"example.com/untagged/@latest":         `{"Version": "v0.0.0-20230601000000-abcdefabcdef"}`,
+		"example.com/broken/@v/list":           "v2.0.0\n",
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/remote/@v/list":
+			fmt.Fprint(w, "v1.0.0\nv1.1.0\n")
+		case "/example.com/broken/@v/list":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		name    string
+		goproxy string
+		module  string
+		want    []string
+		err     bool
+	}{
+		{name: "escaped path", goproxy: files, module: "github.com/BurntSushi/toml", want: []string{"v1.2.0", "v1.3.2"}},
+		{name: "latest without tags", goproxy: files, module: "example.com/untagged", want: []string{"v0.0.0-20230601000000-abcdefabcdef"}},
+		{name: "not found falls through a comma", goproxy: files + "," + srv.URL, module: "example.com/remote", want: []string{"v1.0.0", "v1.1.0"}},
+		{name: "direct is skipped", goproxy: srv.URL + ",direct", module: "example.com/remote", want: []string{"v1.0.0", "v1.1.0"}},
+		{name: "errors stop at a comma", goproxy: srv.URL + "," + files, module: "example.com/broken", err: true},
+		{name: "errors fall through a pipe", goproxy: srv.URL + "|" + files, module: "example.com/broken", want: []string{"v2.0.0"}},
+		{name: "off", goproxy: "off", module: "example.com/remote", err: true},
+		{name: "missing", goproxy: files, module: "example.com/missing", err: true},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewProxy(test.goproxy, "").Versions(test.module)
+			if test.err {
+				assert.NotNil(t, err, test.name)
+
+				return
+			}
+
+			assert.Nil(t, err, test.name)
+			assert.Equal(t, test.want, got, test.name)
+		})
+	}
+}
+
+func TestProxyPrivate(t *testing.T) {
+	files := fileProxy(t, map[string]string{
+		"example.com/private/app/@v/list": "v1.0.0\n",
+	})
+
+	_, err := NewProxy(files, "example.com/private").Versions("example.com/private/app")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,138 @@
+package helm
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mhristof/bump/updater/oci"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Client looks up the versions of Helm charts in chart repositories, through
+// their index.yaml, and in OCI registries, through the tags of the chart.
+type Client struct {
+	client   *http.Client
+	registry *oci.Client
+
+	mu sync.Mutex
+	// indexes caches the versions of every chart of a repository, since a
+	// single index.yaml holds all of them.
+	indexes map[string]map[string][]string
+}
+
+// NewClient returns a client that lists the tags of OCI charts with
+// registry.
+func NewClient(registry *oci.Client) *Client {
+	return &Client{
+		client:   http.DefaultClient,
+		registry: registry,
+		indexes:  map[string]map[string][]string{},
+	}
+}
+
+// Versions returns the versions of the chart name in repository, an http(s)
+// chart repository or an oci:// registry path.
+func (c *Client) Versions(repository, name string) ([]string, error) {
+	if path := strings.TrimPrefix(repository, "oci://"); path != repository {
+		return c.ociVersions(path, name)
+	}
+
+	index, err := c.index(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, ok := index[name]
+	if !ok {
+		return nil, fmt.Errorf("cannot find chart %s in %s", name, repository)
+	}
+
+	return versions, nil
+}
+
+// ociVersions returns the tags of the chart name under path. Helm pushes
+// versions with build metadata with a _ instead of the + that tags cannot
+// have.
+func (c *Client) ociVersions(path, name string) ([]string, error) {
+	host, repository, _ := strings.Cut(path, "/")
+	if repository != "" {
+		repository += "/"
+	}
+
+	tags, err := c.registry.Tags(host, repository+name)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		ret = append(ret, strings.ReplaceAll(tag, "_", "+"))
+	}
+
+	return ret, nil
+}
+
+// index returns the versions of every chart of the index.yaml of repository
+// that are not deprecated.
+func (c *Client) index(repository string) (map[string][]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if index, ok := c.indexes[repository]; ok {
+		return index, nil
+	}
+
+	url := repository + "/index.yaml"
+
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", url, err)
+	}
+
+	var index struct {
+		Entries map[string][]struct {
+			Version    string `yaml:"version"`
+			Deprecated bool   `yaml:"deprecated"`
+		} `yaml:"entries"`
+	}
+
+	err = yaml.Unmarshal(data, &index)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %w", url, err)
+	}
+
+	ret := map[string][]string{}
+
+	for name, entries := range index.Entries {
+		for _, entry := range entries {
+			if entry.Deprecated {
+				continue
+			}
+
+			ret[name] = append(ret[name], entry.Version)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"repository": repository,
+		"charts":     len(ret),
+	}).Debug("found chart repository index")
+
+	c.indexes[repository] = ret
+
+	return ret, nil
+}
@@ -0,0 +1,108 @@
+package helm
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/bump/updater/oci"
+	"github.com/stretchr/testify/assert"
+)
+
+const index = `apiVersion: v1
+entries:
+  postgresql:
+    - version: 12.6.0
+    - version: 12.5.6
+    - version: 12.5.5
+      deprecated: true
+  redis:
+    - version: 17.11.3
+`
+
+func server(t *testing.T) (*httptest.Server, *int) {
+	requests := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/charts/index.yaml":
+			requests++
+
+			fmt.Fprint(w, index)
+		case "/v2/org/charts/api/tags/list":
+			fmt.Fprint(w, `{"name": "org/charts/api", "tags": ["1.4.0", "1.5.0", "1.5.0_build.1"]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func TestVersions(t *testing.T) {
+	srv, requests := server(t)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	cases := []struct {
+		name       string
+		repository string
+		chart      string
+		want       []string
+		err        bool
+	}{
+		{
+			name:       "index without deprecated versions",
+			repository: srv.URL + "/charts",
+			chart:      "postgresql",
+			want:       []string{"12.5.6", "12.6.0"},
+		},
+		{
+			name:       "cached index",
+			repository: srv.URL + "/charts",
+			chart:      "redis",
+			want:       []string{"17.11.3"},
+		},
+		{
+			name:       "chart missing from the index",
+			repository: srv.URL + "/charts",
+			chart:      "mysql",
+			err:        true,
+		},
+		{
+			name:       "missing index",
+			repository: srv.URL + "/missing",
+			chart:      "mysql",
+			err:        true,
+		},
+		{
+			name:       "oci tags with build metadata",
+			repository: "oci://" + host + "/org/charts",
+			chart:      "api",
+			want:       []string{"1.4.0", "1.5.0", "1.5.0+build.1"},
+		},
+	}
+
+	client := NewClient(oci.NewClient(""))
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := client.Versions(test.repository, test.chart)
+			if test.err {
+				assert.NotNil(t, err, test.name)
+
+				return
+			}
+
+			sort.Strings(got)
+
+			assert.Nil(t, err, test.name)
+			assert.Equal(t, test.want, got, test.name)
+		})
+	}
+
+	assert.Equal(t, 1, *requests)
+}
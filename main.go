@@ -2,6 +2,17 @@ package main
 
 import (
 	"github.com/mhristof/bump/cmd"
+	_ "github.com/mhristof/bump/updater/actions"
+	_ "github.com/mhristof/bump/updater/ami"
+	_ "github.com/mhristof/bump/updater/dockerhub"
+	_ "github.com/mhristof/bump/updater/ecr"
+	_ "github.com/mhristof/bump/updater/ghcr"
+	_ "github.com/mhristof/bump/updater/github"
+	_ "github.com/mhristof/bump/updater/gitlab"
+	_ "github.com/mhristof/bump/updater/npm"
+	_ "github.com/mhristof/bump/updater/oci"
+	_ "github.com/mhristof/bump/updater/pypi"
+	_ "github.com/mhristof/bump/updater/rubygems"
 )
 
 func main() {
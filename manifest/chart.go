@@ -0,0 +1,207 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Chart is a Helm chart found in a YAML document: a dependency of a
+// Chart.yaml, a release of a helmfile, or the source of an Argo CD
+// Application.
+type Chart struct {
+	Name string
+	// Repository is the URL of the chart repository, either an http(s) URL
+	// with an index.yaml or an oci:// URL of the registry path that holds
+	// the chart.
+	Repository string
+	Version    string
+	// Line is the 1-based line the version is written in.
+	Line int
+
+	version span
+}
+
+// Rewrite returns the edit that sets the version of the chart to version.
+func (c Chart) Rewrite(version string) Edit {
+	text := c.version.text
+
+	return Edit{
+		Line:    c.version.line,
+		Text:    text,
+		NewText: text[:c.version.offset] + version + text[c.version.offset+c.version.length:],
+	}
+}
+
+// ParseCharts returns the charts of every YAML document in data that have a
+// version and a repository.
+func ParseCharts(data []byte) ([]Chart, error) {
+	lines := strings.Split(string(data), "\n")
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var ret []Chart
+
+	for {
+		var doc yaml.Node
+
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse yaml: %w", err)
+		}
+
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+
+		ret = append(ret, documentCharts(doc.Content[0], lines)...)
+	}
+
+	return ret, nil
+}
+
+// documentCharts returns the charts of the top level mapping of a document.
+func documentCharts(root *yaml.Node, lines []string) []Chart {
+	apiVersion := ""
+	if node := value(root, "apiVersion"); node != nil {
+		apiVersion = node.Value
+	}
+
+	var ret []Chart
+
+	switch {
+	case apiVersion == "v1" || apiVersion == "v2":
+		for _, dependency := range items(value(root, "dependencies")) {
+			if chart, ok := newChart(value(dependency, "name"), value(dependency, "repository"), value(dependency, "version"), lines); ok {
+				ret = append(ret, chart)
+			}
+		}
+	case strings.HasPrefix(apiVersion, "argoproj.io/"):
+		walk(root, func(node *yaml.Node) {
+			repoURL := value(node, "repoURL")
+			if repoURL == nil || value(node, "chart") == nil {
+				return
+			}
+
+			// Argo CD repositories without a scheme are OCI registries.
+			repository := *repoURL
+			if !strings.Contains(repository.Value, "://") {
+				repository.Value = "oci://" + repository.Value
+			}
+
+			if chart, ok := newChart(value(node, "chart"), &repository, value(node, "targetRevision"), lines); ok {
+				ret = append(ret, chart)
+			}
+		})
+	case value(root, "releases") != nil:
+		repositories := map[string]string{}
+
+		for _, repository := range items(value(root, "repositories")) {
+			name, url := value(repository, "name"), value(repository, "url")
+			if name == nil || url == nil {
+				continue
+			}
+
+			repositories[name.Value] = url.Value
+			if oci := value(repository, "oci"); oci != nil && oci.Value == "true" {
+				repositories[name.Value] = "oci://" + strings.TrimPrefix(url.Value, "oci://")
+			}
+		}
+
+		for _, release := range items(value(root, "releases")) {
+			chart := value(release, "chart")
+			if chart == nil {
+				continue
+			}
+
+			name := *chart
+			repository := yaml.Node{Kind: yaml.ScalarNode}
+
+			if i := strings.LastIndex(chart.Value, "/"); i >= 0 {
+				name.Value = chart.Value[i+1:]
+				repository.Value = chart.Value[:i]
+			}
+
+			// Releases refer to charts as alias/name, or to OCI charts by
+			// their full URL.
+			if url, ok := repositories[repository.Value]; ok {
+				repository.Value = url
+			}
+
+			if c, ok := newChart(&name, &repository, value(release, "version"), lines); ok {
+				ret = append(ret, c)
+			}
+		}
+	}
+
+	return ret
+}
+
+// newChart returns the chart of the name, repository and version nodes.
+// Charts of local paths and repository aliases have no repository to look
+// versions up.
+func newChart(name, repository, version *yaml.Node, lines []string) (Chart, bool) {
+	var ret Chart
+
+	if name == nil || repository == nil || version == nil || name.Value == "" || version.Value == "" {
+		return ret, false
+	}
+
+	if !strings.HasPrefix(repository.Value, "https://") &&
+		!strings.HasPrefix(repository.Value, "http://") &&
+		!strings.HasPrefix(repository.Value, "oci://") {
+		return ret, false
+	}
+
+	versionSpan, ok := locate(version, lines)
+	if !ok {
+		return ret, false
+	}
+
+	ret.Name = name.Value
+	ret.Repository = strings.TrimSuffix(repository.Value, "/")
+	ret.Version = version.Value
+	ret.Line = version.Line
+	ret.version = versionSpan
+
+	return ret, true
+}
+
+// value returns the value of key in the mapping node, if any.
+func value(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// items returns the mappings of the sequence node.
+func items(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	var ret []*yaml.Node
+
+	for _, item := range node.Content {
+		if item.Kind == yaml.MappingNode {
+			ret = append(ret, item)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,142 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const charts = `apiVersion: v2
+name: app
+version: 0.1.0
+dependencies:
+  - name: postgresql
+    version: "12.5.6"
+    repository: https://charts.bitnami.com/bitnami/
+  - name: redis
+    version: 17.11.3
+    repository: oci://registry-1.docker.io/bitnamicharts
+  - name: common
+    version: 1.0.0
+    repository: file://../common
+  - name: aliased
+    version: 1.0.0
+    repository: "@bitnami"
+---
+repositories:
+  - name: prometheus-community
+    url: https://prometheus-community.github.io/helm-charts
+  - name: ecr
+    url: 123456789012.dkr.ecr.eu-west-1.amazonaws.com/charts
+    oci: true
+releases:
+  - name: prometheus
+    chart: prometheus-community/kube-prometheus-stack
+    version: 45.27.2 # pinned
+  - name: internal
+    chart: ecr/internal
+    version: 2.0.0
+  - name: direct
+    chart: oci://ghcr.io/org/charts/direct
+    version: v0.3.0
+  - name: local
+    chart: ./charts/local
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+spec:
+  source:
+    repoURL: https://argoproj.github.io/argo-helm
+    chart: argo-cd
+    targetRevision: 5.36.1
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+spec:
+  sources:
+    - repoURL: ghcr.io/org/charts
+      chart: api
+      targetRevision: 1.4.0
+    - repoURL: https://github.com/org/config.git
+      targetRevision: main
+      path: values
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  dependencies:
+    - name: nope
+      version: 1.0.0
+      repository: https://example.com
+`
+
+func TestParseCharts(t *testing.T) {
+	got, err := ParseCharts([]byte(charts))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	type want struct {
+		name       string
+		repository string
+		version    string
+		line       int
+	}
+
+	found := make([]want, len(got))
+	for i, chart := range got {
+		found[i] = want{chart.Name, chart.Repository, chart.Version, chart.Line}
+	}
+
+	assert.Equal(t, []want{
+		{"postgresql", "https://charts.bitnami.com/bitnami", "12.5.6", 6},
+		{"redis", "oci://registry-1.docker.io/bitnamicharts", "17.11.3", 9},
+		{"kube-prometheus-stack", "https://prometheus-community.github.io/helm-charts", "45.27.2", 27},
+		{"internal", "oci://123456789012.dkr.ecr.eu-west-1.amazonaws.com/charts", "2.0.0", 30},
+		{"direct", "oci://ghcr.io/org/charts", "v0.3.0", 33},
+		{"argo-cd", "https://argoproj.github.io/argo-helm", "5.36.1", 43},
+		{"api", "oci://ghcr.io/org/charts", "1.4.0", 51},
+	}, found)
+
+	_, err = ParseCharts([]byte("dependencies:\n  {{- range .Values.charts }}\n"))
+	assert.NotNil(t, err)
+}
+
+func TestChartRewrite(t *testing.T) {
+	got, err := ParseCharts([]byte(charts))
+	if !assert.Nil(t, err) || !assert.Len(t, got, 7) {
+		return
+	}
+
+	cases := []struct {
+		name    string
+		chart   Chart
+		version string
+		want    Edit
+	}{
+		{
+			name:    "quoted version",
+			chart:   got[0],
+			version: "12.6.0",
+			want:    Edit{Line: 6, Text: `    version: "12.5.6"`, NewText: `    version: "12.6.0"`},
+		},
+		{
+			name:    "version with a comment",
+			chart:   got[2],
+			version: "46.0.0",
+			want:    Edit{Line: 27, Text: "    version: 45.27.2 # pinned", NewText: "    version: 46.0.0 # pinned"},
+		},
+		{
+			name:    "target revision",
+			chart:   got[5],
+			version: "5.37.0",
+			want:    Edit{Line: 43, Text: "    targetRevision: 5.36.1", NewText: "    targetRevision: 5.37.0"},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.chart.Rewrite(test.version), test.name)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/bump/dockerfile"
+	"gopkg.in/yaml.v3"
+)
+
+// IsManifest reports whether path is a YAML file, which may hold Kubernetes
+// manifests, Kustomize overrides or Helm values.
+func IsManifest(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Image is a container image found in a YAML document. It is either a single
+// image: name:tag value, a Kustomize images entry with name, newName and
+// newTag, or a repository and tag pair such as the image of Helm values.
+type Image struct {
+	// Registry is the host of the image, empty for Docker Hub.
+	Registry string
+	// Repository is the path of the image, such as library/nginx.
+	Repository string
+	Tag        string
+	Digest     string
+	// Line is the 1-based line the tag is written in.
+	Line int
+
+	tag    span
+	digest *span
+}
+
+// span is where a value is written: the bytes [offset, offset+length) of
+// the line text.
+type span struct {
+	line   int
+	text   string
+	offset int
+	length int
+}
+
+// Edit is a line of the document and its rewritten text.
+type Edit struct {
+	Line    int
+	Text    string
+	NewText string
+}
+
+// Reference returns the image as registry/repository:tag, without the
+// registry for Docker Hub images.
+func (i Image) Reference() string {
+	ret := i.Repository
+	if i.Registry != "" {
+		ret = i.Registry + "/" + ret
+	}
+
+	return ret + ":" + i.Tag
+}
+
+// Rewrite returns the edits that set the tag of the image to tag and its
+// digest to digest. The digest is only written where the document already
+// has room for it: after the tag of a single image value, or in an existing
+// digest key.
+func (i Image) Rewrite(tag, digest string) []Edit {
+	type change struct {
+		span
+		value string
+	}
+
+	var changes []change
+
+	if tag != i.Tag {
+		changes = append(changes, change{i.tag, tag})
+	}
+
+	if digest != "" && digest != i.Digest && i.digest != nil {
+		value := digest
+		if i.digest.length == 0 {
+			value = "@" + digest
+		}
+
+		changes = append(changes, change{*i.digest, value})
+	}
+
+	sort.SliceStable(changes, func(a, b int) bool {
+		if changes[a].line != changes[b].line {
+			return changes[a].line < changes[b].line
+		}
+
+		return changes[a].offset > changes[b].offset
+	})
+
+	var ret []Edit
+
+	for _, c := range changes {
+		if len(ret) == 0 || ret[len(ret)-1].Line != c.line {
+			ret = append(ret, Edit{Line: c.line, Text: c.text, NewText: c.text})
+		}
+
+		edit := &ret[len(ret)-1]
+		edit.NewText = edit.NewText[:c.offset] + c.value + edit.NewText[c.offset+c.length:]
+	}
+
+	return ret
+}
+
+// Parse returns the images of every YAML document in data that have a tag.
+func Parse(data []byte) ([]Image, error) {
+	lines := strings.Split(string(data), "\n")
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var ret []Image
+
+	for {
+		var doc yaml.Node
+
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse yaml: %w", err)
+		}
+
+		walk(&doc, func(node *yaml.Node) {
+			if image, ok := parseMapping(node, lines); ok {
+				ret = append(ret, image)
+			}
+		})
+	}
+
+	return ret, nil
+}
+
+func walk(node *yaml.Node, fn func(*yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		fn(node)
+	}
+
+	for _, child := range node.Content {
+		walk(child, fn)
+	}
+}
+
+// parseMapping returns the image described by the mapping node, if any.
+func parseMapping(node *yaml.Node, lines []string) (Image, bool) {
+	keys := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if value := node.Content[i+1]; value.Kind == yaml.ScalarNode {
+			keys[node.Content[i].Value] = value
+		}
+	}
+
+	switch {
+	case keys["newTag"] != nil && keys["name"] != nil:
+		name := keys["name"]
+		if keys["newName"] != nil {
+			name = keys["newName"]
+		}
+
+		return splitImage(name.Value, keys["newTag"], keys["digest"], lines)
+	case keys["repository"] != nil && keys["tag"] != nil:
+		name := keys["repository"].Value
+		if keys["registry"] != nil && keys["registry"].Value != "" {
+			name = keys["registry"].Value + "/" + name
+		}
+
+		return splitImage(name, keys["tag"], keys["digest"], lines)
+	case keys["image"] != nil:
+		return scalarImage(keys["image"], lines)
+	}
+
+	return Image{}, false
+}
+
+// splitImage returns the image name with the tag and optional digest of
+// their own keys.
+func splitImage(name string, tag, digest *yaml.Node, lines []string) (Image, bool) {
+	var ret Image
+
+	tagSpan, ok := locate(tag, lines)
+	if !ok || tag.Value == "" {
+		return ret, false
+	}
+
+	ret.Registry, ret.Repository = dockerfile.SplitName(name)
+	ret.Tag = tag.Value
+	ret.Line = tag.Line
+	ret.tag = tagSpan
+
+	if digest == nil || digest.Value == "" {
+		return ret, true
+	}
+
+	digestSpan, ok := locate(digest, lines)
+	if !ok {
+		return ret, true
+	}
+
+	ret.Digest = digest.Value
+	ret.digest = &digestSpan
+
+	return ret, true
+}
+
+// scalarImage returns the image of a name:tag value, optionally followed by
+// @digest.
+func scalarImage(node *yaml.Node, lines []string) (Image, bool) {
+	var ret Image
+
+	value, ok := locate(node, lines)
+	if !ok {
+		return ret, false
+	}
+
+	name := node.Value
+	digestOffset := len(name)
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		ret.Digest = name[i+1:]
+		name = name[:i]
+		digestOffset = i + 1
+	}
+
+	i := strings.LastIndex(name, ":")
+	if i <= strings.LastIndex(name, "/") || i == len(name)-1 {
+		return ret, false
+	}
+
+	ret.Registry, ret.Repository = dockerfile.SplitName(name[:i])
+	ret.Tag = name[i+1:]
+	ret.Line = node.Line
+	ret.tag = span{
+		line:   value.line,
+		text:   value.text,
+		offset: value.offset + i + 1,
+		length: len(ret.Tag),
+	}
+	ret.digest = &span{
+		line:   value.line,
+		text:   value.text,
+		offset: value.offset + digestOffset,
+		length: len(ret.Digest),
+	}
+
+	return ret, true
+}
+
+// locate returns where the value of the scalar node is written. Block
+// scalars and values that are not written as is, like escaped strings,
+// cannot be rewritten in place.
+func locate(node *yaml.Node, lines []string) (span, bool) {
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || node.Line < 1 || node.Line > len(lines) {
+		return span{}, false
+	}
+
+	text := lines[node.Line-1]
+
+	column := node.Column - 1
+	if column < 0 || column > len(text) {
+		return span{}, false
+	}
+
+	i := strings.Index(text[column:], node.Value)
+	if i < 0 {
+		return span{}, false
+	}
+
+	return span{
+		line:   node.Line,
+		text:   text,
+		offset: column + i,
+		length: len(node.Value),
+	}, true
+}
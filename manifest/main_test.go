@@ -0,0 +1,160 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsManifest(t *testing.T) {
+	for path, want := range map[string]bool{
+		"deploy/deployment.yaml":      true,
+		"kustomization.yml":           true,
+		"charts/app/values.YAML":      true,
+		"Dockerfile":                  false,
+		"charts/app/templates/_h.tpl": false,
+	} {
+		assert.Equal(t, want, IsManifest(path), path)
+	}
+}
+
+const sample = `apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: "ghcr.io/org/app:v1.2.3"
+        - name: sidecar
+          image: nginx
+---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+images:
+  - name: postgres
+    newName: my.registry/postgres
+    newTag: "15.3"
+  - name: redis
+    newTag: 7.0.11
+    digest: sha256:0000000000000000000000000000000000000000000000000000000000000000
+---
+image:
+  registry: docker.io
+  repository: bitnami/redis
+  tag: 7.0.11-debian-11-r0 # pinned
+  pullPolicy: IfNotPresent
+proxy:
+  image: quay.io/oauth2-proxy/oauth2-proxy:v7.4.0@sha256:1111111111111111111111111111111111111111111111111111111111111111
+`
+
+func TestParse(t *testing.T) {
+	images, err := Parse([]byte(sample))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	type want struct {
+		reference string
+		digest    string
+		line      int
+	}
+
+	got := make([]want, len(images))
+	for i, image := range images {
+		got[i] = want{image.Reference(), image.Digest, image.Line}
+	}
+
+	assert.Equal(t, []want{
+		{reference: "ghcr.io/org/app:v1.2.3", line: 8},
+		{reference: "my.registry/postgres:15.3", line: 17},
+		{reference: "library/redis:7.0.11", digest: "sha256:" + strings.Repeat("0", 64), line: 19},
+		{reference: "bitnami/redis:7.0.11-debian-11-r0", line: 25},
+		{reference: "quay.io/oauth2-proxy/oauth2-proxy:v7.4.0", digest: "sha256:" + strings.Repeat("1", 64), line: 28},
+	}, got)
+
+	_, err = Parse([]byte("metadata:\n  labels:\n    {{- include \"labels\" . | nindent 4 }}\n"))
+	assert.NotNil(t, err)
+}
+
+func TestRewrite(t *testing.T) {
+	images, err := Parse([]byte(sample))
+	if !assert.Nil(t, err) || !assert.Len(t, images, 5) {
+		return
+	}
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	cases := []struct {
+		name   string
+		image  Image
+		tag    string
+		digest string
+		want   []Edit
+	}{
+		{
+			name:  "quoted image",
+			image: images[0],
+			tag:   "v1.3.0",
+			want: []Edit{
+				{Line: 8, Text: `          image: "ghcr.io/org/app:v1.2.3"`, NewText: `          image: "ghcr.io/org/app:v1.3.0"`},
+			},
+		},
+		{
+			name:   "image without a digest gets one",
+			image:  images[0],
+			tag:    "v1.3.0",
+			digest: digest,
+			want: []Edit{
+				{Line: 8, Text: `          image: "ghcr.io/org/app:v1.2.3"`, NewText: `          image: "ghcr.io/org/app:v1.3.0@` + digest + `"`},
+			},
+		},
+		{
+			name:  "kustomize new tag",
+			image: images[1],
+			tag:   "15.4",
+			want: []Edit{
+				{Line: 17, Text: `    newTag: "15.3"`, NewText: `    newTag: "15.4"`},
+			},
+		},
+		{
+			name:   "kustomize tag and digest",
+			image:  images[2],
+			tag:    "7.2.0",
+			digest: digest,
+			want: []Edit{
+				{Line: 19, Text: "    newTag: 7.0.11", NewText: "    newTag: 7.2.0"},
+				{Line: 20, Text: "    digest: sha256:" + strings.Repeat("0", 64), NewText: "    digest: " + digest},
+			},
+		},
+		{
+			name:   "helm values without a digest key",
+			image:  images[3],
+			tag:    "7.2.0-debian-11-r0",
+			digest: digest,
+			want: []Edit{
+				{Line: 25, Text: "  tag: 7.0.11-debian-11-r0 # pinned", NewText: "  tag: 7.2.0-debian-11-r0 # pinned"},
+			},
+		},
+		{
+			name:   "digest only",
+			image:  images[4],
+			tag:    "v7.4.0",
+			digest: digest,
+			want: []Edit{
+				{
+					Line:    28,
+					Text:    "  image: quay.io/oauth2-proxy/oauth2-proxy:v7.4.0@sha256:" + strings.Repeat("1", 64),
+					NewText: "  image: quay.io/oauth2-proxy/oauth2-proxy:v7.4.0@" + digest,
+				},
+			},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.image.Rewrite(test.tag, test.digest), test.name)
+		})
+	}
+}
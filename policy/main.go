@@ -0,0 +1,250 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Level is the largest version jump an update may make.
+type Level int
+
+const (
+	Major Level = iota
+	Minor
+	Patch
+)
+
+func (l Level) String() string {
+	switch l {
+	case Major:
+		return "major"
+	case Minor:
+		return "minor"
+	case Patch:
+		return "patch"
+	}
+
+	return "unsupported"
+}
+
+// ParseLevel parses patch, minor or major.
+func ParseLevel(level string) (Level, error) {
+	switch strings.ToLower(level) {
+	case "major":
+		return Major, nil
+	case "minor":
+		return Minor, nil
+	case "patch":
+		return Patch, nil
+	}
+
+	return Major, fmt.Errorf("invalid level %q, expected patch, minor or major", level)
+}
+
+// Allows reports whether going from current to next stays within the level.
+func (l Level) Allows(current, next *semver.Version) bool {
+	switch l {
+	case Patch:
+		return next.Major() == current.Major() && next.Minor() == current.Minor()
+	case Minor:
+		return next.Major() == current.Major()
+	}
+
+	return true
+}
+
+// Prerelease decides when a pre-release, such as 2.0.0-rc.1, may be picked.
+type Prerelease int
+
+const (
+	// SameTrack only moves a pre-release to a later pre-release of the same
+	// version or to a release.
+	SameTrack Prerelease = iota
+	// Never picks a pre-release.
+	Never
+	// Always picks pre-releases like any other version.
+	Always
+)
+
+func (p Prerelease) String() string {
+	switch p {
+	case SameTrack:
+		return "same-track"
+	case Never:
+		return "never"
+	case Always:
+		return "always"
+	}
+
+	return "unsupported"
+}
+
+// ParsePrerelease parses never, same-track or always.
+func ParsePrerelease(prerelease string) (Prerelease, error) {
+	switch strings.ToLower(prerelease) {
+	case "same-track":
+		return SameTrack, nil
+	case "never":
+		return Never, nil
+	case "always":
+		return Always, nil
+	}
+
+	return SameTrack, fmt.Errorf("invalid prerelease %q, expected never, same-track or always", prerelease)
+}
+
+// Allows reports whether current may move to next. Releases are always
+// allowed.
+func (p Prerelease) Allows(current, next *semver.Version) bool {
+	if next.Prerelease() == "" {
+		return true
+	}
+
+	switch p {
+	case Always:
+		return true
+	case SameTrack:
+		return current.Prerelease() != "" &&
+			next.Major() == current.Major() &&
+			next.Minor() == current.Minor() &&
+			next.Patch() == current.Patch()
+	}
+
+	return false
+}
+
+// Override sets the level of the files matching Files and the changes of
+// the updater called Source. Empty fields match everything.
+type Override struct {
+	Files  string `mapstructure:"files"`
+	Source string `mapstructure:"source"`
+	Level  string `mapstructure:"level"`
+
+	level Level
+}
+
+// Policy decides how far each change may move.
+type Policy struct {
+	Level      Level
+	Prerelease Prerelease
+	Overrides  []Override
+	// Freeze pins the sources that support it to a commit, with the version
+	// in a comment.
+	Freeze bool
+	// Pin pins container images to the digest of their tag. Images that
+	// already have a digest get it updated either way.
+	Pin bool
+	// Roots are the directories that were scanned. Files patterns with a /
+	// match the path of a file relative to the root it was found in, or to
+	// the working directory for files outside of every root.
+	Roots []string
+}
+
+// New returns a policy with the default level, the pre-release handling of
+// every source and the overrides, which are checked in order.
+func New(level, prerelease string, overrides []Override) (Policy, error) {
+	ret := Policy{}
+
+	var err error
+
+	ret.Level, err = ParseLevel(level)
+	if err != nil {
+		return ret, err
+	}
+
+	ret.Prerelease, err = ParsePrerelease(prerelease)
+	if err != nil {
+		return ret, err
+	}
+
+	for _, override := range overrides {
+		override.level, err = ParseLevel(override.Level)
+		if err != nil {
+			return ret, fmt.Errorf("invalid override for files %q and source %q: %w", override.Files, override.Source, err)
+		}
+
+		if override.Files != "" && !doublestar.ValidatePattern(override.Files) {
+			return ret, fmt.Errorf("invalid files pattern %q", override.Files)
+		}
+
+		ret.Overrides = append(ret.Overrides, override)
+	}
+
+	return ret, nil
+}
+
+// For returns the level of a change in file found by the updater called
+// source. The first matching override wins.
+func (p Policy) For(file, source string) Level {
+	for _, override := range p.Overrides {
+		if override.Source != "" && override.Source != source {
+			continue
+		}
+
+		if override.Files != "" && !matchFile(override.Files, p.relative(file)) {
+			continue
+		}
+
+		return override.level
+	}
+
+	return p.Level
+}
+
+// relative returns file relative to the outermost root that holds it, so
+// the same patterns match whether bump ran on ., ../repo or /abs/repo.
+func (p Policy) relative(file string) string {
+	if file == "" {
+		return ""
+	}
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+
+	roots := p.Roots
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	ret, outermost := file, ""
+
+	for _, root := range roots {
+		root, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		if outermost == "" || len(root) < len(outermost) {
+			ret, outermost = rel, root
+		}
+	}
+
+	return ret
+}
+
+func matchFile(pattern, file string) bool {
+	if file == "" {
+		return false
+	}
+
+	file = filepath.ToSlash(filepath.Clean(file))
+	if !strings.Contains(pattern, "/") {
+		file = path.Base(file)
+	}
+
+	ok, _ := doublestar.Match(pattern, file)
+
+	return ok
+}
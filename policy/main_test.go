@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFor(t *testing.T) {
+	p, err := New("minor", "same-track", []Override{
+		{Files: "prod/**", Level: "patch"},
+		{Source: "dockerhub", Level: "major"},
+		{Files: "*.tf", Source: "terraform-provider", Level: "major"},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	cases := []struct {
+		name   string
+		file   string
+		source string
+		want   Level
+	}{
+		{name: "default", file: "main.tf", source: "terraform", want: Minor},
+		{name: "file override", file: "./prod/eu-west-1/main.tf", source: "terraform", want: Patch},
+		{name: "first override wins", file: "prod/Dockerfile", source: "dockerhub", want: Patch},
+		{name: "source override", file: "Dockerfile", source: "dockerhub", want: Major},
+		{name: "file and source override", file: "modules/vpc/versions.tf", source: "terraform-provider", want: Major},
+		{name: "string argument", source: "github", want: Minor},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, p.For(test.file, test.source), test.name)
+		})
+	}
+}
+
+func TestForRoots(t *testing.T) {
+	root := t.TempDir()
+
+	p, err := New("minor", "same-track", []Override{
+		{Files: "modules/**/*.tf", Level: "patch"},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	p.Roots = []string{root, filepath.Join(root, "modules", "vpc")}
+
+	cases := []struct {
+		name string
+		file string
+		want Level
+	}{
+		{name: "absolute path", file: filepath.Join(root, "modules", "vpc", "main.tf"), want: Patch},
+		{name: "outside the pattern", file: filepath.Join(root, "main.tf"), want: Minor},
+		{name: "outside every root", file: filepath.Join(t.TempDir(), "modules", "vpc", "main.tf"), want: Minor},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, p.For(test.file, "terraform"), test.name)
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New("huge", "never", nil)
+	assert.NotNil(t, err)
+
+	_, err = New("major", "never", []Override{{Source: "github", Level: "tiny"}})
+	assert.NotNil(t, err)
+
+	_, err = New("major", "never", []Override{{Files: "[", Level: "patch"}})
+	assert.NotNil(t, err)
+}
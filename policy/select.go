@@ -0,0 +1,134 @@
+package policy
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Select returns the highest of versions that is greater than current and
+// that the level of file and source and the pre-release handling of p allow,
+// or nil.
+func (p Policy) Select(file, source string, current *semver.Version, versions []*semver.Version) *semver.Version {
+	return Select(p.For(file, source), p.Prerelease, current, versions)
+}
+
+// Select returns the highest of versions that is greater than current and
+// that level and prerelease allow, or nil. The order of versions does not
+// matter.
+//
+// Build metadata never makes a version newer, so 1.2.3+build.6 is not an
+// update of 1.2.3+build.5. When several tags name the same version, the one
+// written like current wins, so v1.2.3 is preferred over 1.2.3 when current
+// is v1.2.0, and then the one with the highest build metadata.
+func Select(level Level, prerelease Prerelease, current *semver.Version, versions []*semver.Version) *semver.Version {
+	if current == nil {
+		return nil
+	}
+
+	var candidates []*semver.Version
+
+	for _, version := range versions {
+		if version == nil || !version.GreaterThan(current) || !level.Allows(current, version) || !prerelease.Allows(current, version) {
+			continue
+		}
+
+		candidates = append(candidates, version)
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if !candidates[i].Equal(candidates[j]) {
+			return candidates[i].GreaterThan(candidates[j])
+		}
+
+		si, sj := style(current, candidates[i]), style(current, candidates[j])
+		if si != sj {
+			return si > sj
+		}
+
+		if c := compareMetadata(candidates[i].Metadata(), candidates[j].Metadata()); c != 0 {
+			return c > 0
+		}
+
+		return candidates[i].Original() < candidates[j].Original()
+	})
+
+	return candidates[0]
+}
+
+// style scores how closely the tag of v is written like the tag of current.
+func style(current, v *semver.Version) int {
+	score := 0
+
+	if strings.HasPrefix(current.Original(), "v") == strings.HasPrefix(v.Original(), "v") {
+		score += 4
+	}
+
+	if precision(current.Original()) == precision(v.Original()) {
+		score += 2
+	}
+
+	if (current.Metadata() == "") == (v.Metadata() == "") {
+		score++
+	}
+
+	return score
+}
+
+func precision(tag string) int {
+	core := strings.TrimPrefix(tag, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+
+	return strings.Count(core, ".") + 1
+}
+
+// compareMetadata compares build metadata the way semver compares
+// pre-releases: identifier by identifier, numbers numerically and before
+// words, and a longer list wins when the shorter one is its prefix.
+func compareMetadata(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if an > bn {
+				return 1
+			}
+
+			return -1
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case as[i] > bs[i]:
+			return 1
+		}
+
+		return -1
+	}
+
+	if len(as) > len(bs) {
+		return 1
+	}
+
+	return -1
+}
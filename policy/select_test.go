@@ -0,0 +1,161 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func versions(in ...string) []*semver.Version {
+	ret := make([]*semver.Version, len(in))
+	for i, v := range in {
+		ret[i] = semver.MustParse(v)
+	}
+
+	return ret
+}
+
+func TestSelect(t *testing.T) {
+	cases := []struct {
+		name       string
+		level      Level
+		prerelease Prerelease
+		current    string
+		versions   []*semver.Version
+		want       string
+	}{
+		{
+			name:     "highest regardless of order",
+			current:  "1.2.3",
+			versions: versions("1.2.4", "2.0.0", "1.0.0", "1.10.0", "1.3.0"),
+			want:     "2.0.0",
+		},
+		{
+			name:     "api order with smaller versions first",
+			current:  "v0.1.0",
+			versions: versions("v0.0.1", "v0.1.0", "v0.17.1", "v0.2.0"),
+			want:     "v0.17.1",
+		},
+		{
+			name:     "minor",
+			level:    Minor,
+			current:  "1.2.3",
+			versions: versions("2.0.0", "1.3.0", "1.2.9", "0.9.0"),
+			want:     "1.3.0",
+		},
+		{
+			name:     "patch",
+			level:    Patch,
+			current:  "1.2.3",
+			versions: versions("2.0.0", "1.3.0", "1.2.9", "1.2.4"),
+			want:     "1.2.9",
+		},
+		{
+			name:     "nothing newer",
+			current:  "1.2.3",
+			versions: versions("1.2.3", "1.0.0"),
+		},
+		{
+			name:     "nothing within the level",
+			level:    Patch,
+			current:  "1.2.3",
+			versions: versions("1.3.0", "2.0.0"),
+		},
+		{
+			name:     "same version prefers the style of current",
+			current:  "v1.2.3",
+			versions: versions("1.3.0", "v1.3.0"),
+			want:     "v1.3.0",
+		},
+		{
+			name:     "same version prefers the precision of current",
+			current:  "1.24",
+			versions: versions("1.25.0", "1.25"),
+			want:     "1.25",
+		},
+		{
+			name:     "release over build of the same version",
+			current:  "1.0.0",
+			versions: versions("2.0.0+b", "2.0.0", "2.0.0+a"),
+			want:     "2.0.0",
+		},
+		{
+			name:     "highest build metadata",
+			current:  "1.0.0+build.3",
+			versions: versions("2.0.0+build.9", "2.0.0+build.10", "2.0.0"),
+			want:     "2.0.0+build.10",
+		},
+		{
+			name:     "build metadata is not an update",
+			current:  "1.0.0+build.3",
+			versions: versions("1.0.0+build.4"),
+		},
+		{
+			name:     "same track skips pre-releases of releases",
+			current:  "1.2.3",
+			versions: versions("1.2.4", "2.0.0-rc.1"),
+			want:     "1.2.4",
+		},
+		{
+			name:     "same track to a later pre-release",
+			current:  "2.0.0-rc.1",
+			versions: versions("2.0.0-rc.2", "2.0.0-beta.5", "2.1.0-rc.1", "1.9.0"),
+			want:     "2.0.0-rc.2",
+		},
+		{
+			name:     "same track to the release",
+			current:  "2.0.0-rc.1",
+			versions: versions("2.0.0-rc.2", "2.0.0", "2.1.0-rc.1"),
+			want:     "2.0.0",
+		},
+		{
+			name:       "never",
+			prerelease: Never,
+			current:    "2.0.0-rc.1",
+			versions:   versions("2.0.0-rc.2", "2.1.0-rc.1"),
+		},
+		{
+			name:       "never moves a pre-release to a release",
+			prerelease: Never,
+			current:    "2.0.0-rc.1",
+			versions:   versions("2.0.0-rc.2", "2.0.1"),
+			want:       "2.0.1",
+		},
+		{
+			name:       "always",
+			prerelease: Always,
+			current:    "1.2.3",
+			versions:   versions("1.2.4", "2.0.0-alpha.1"),
+			want:       "2.0.0-alpha.1",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got := Select(test.level, test.prerelease, semver.MustParse(test.current), test.versions)
+			if test.want == "" {
+				assert.Nil(t, got, test.name)
+
+				return
+			}
+
+			if assert.NotNil(t, got, test.name) {
+				assert.Equal(t, test.want, got.Original(), test.name)
+			}
+		})
+	}
+}
+
+func TestPolicySelect(t *testing.T) {
+	p, err := New("major", "never", []Override{{Source: "dockerhub", Level: "patch"}})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	candidates := versions("1.2.4", "1.3.0")
+
+	assert.Equal(t, "1.3.0", p.Select("main.tf", "github", semver.MustParse("1.2.3"), candidates).Original())
+	assert.Equal(t, "1.2.4", p.Select("Dockerfile", "dockerhub", semver.MustParse("1.2.3"), candidates).Original())
+	assert.Nil(t, p.Select("Dockerfile", "dockerhub", nil, candidates))
+}
@@ -2,51 +2,136 @@ package precommit
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"strings"
 
-	"github.com/mhristof/bump/bash"
-	"github.com/mhristof/bump/tool"
-	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
 )
 
-func Update(path string, dryrun bool) ([]tool.Change, error) {
-	stdout, err := bash.Exec(fmt.Sprintf("cd %s && pre-commit autoupdate", path), dryrun)
-	if err != nil {
-		return []tool.Change{}, errors.Wrap(err, "cannot update pre-commit")
+// ConfigFile is the name of the pre-commit configuration.
+const ConfigFile = ".pre-commit-config.yaml"
+
+// IsConfig reports whether path is a pre-commit configuration.
+func IsConfig(path string) bool {
+	return filepath.Base(path) == ConfigFile
+}
+
+// Repo is an entry of the repos list of a pre-commit configuration.
+type Repo struct {
+	URL string
+	Rev string
+	// Frozen is the version of the "# frozen: v1.2.3" comment that follows
+	// a rev pinned to a commit.
+	Frozen string
+	// Line is the 1-based line of the rev.
+	Line int
+}
+
+// Version returns the version the repo is pinned to, or nil.
+func (r Repo) Version() *semver.Version {
+	rev := r.Rev
+	if r.Frozen != "" {
+		rev = r.Frozen
 	}
 
-	changes := stdoutToChanges(stdout)
+	ret, err := semver.NewVersion(rev)
+	if err != nil {
+		return nil
+	}
 
-	log.WithFields(log.Fields{
-		"stdout":  stdout,
-		"changes": changes,
-	}).Debug("precommit")
+	return ret
+}
 
-	return changes, nil
+type config struct {
+	Repos []struct {
+		Repo yaml.Node `yaml:"repo"`
+		Rev  yaml.Node `yaml:"rev"`
+	} `yaml:"repos"`
 }
 
-func stdoutToChanges(stdout string) []tool.Change {
-	var changes []tool.Change
+var frozenRegex = regexp.MustCompile(`frozen:\s*(\S+)`)
 
-	for _, line := range strings.Split(stdout, "\n") {
-		// Updating https://github.com/pre-commit/pre-commit-hooks ... updating v4.3.0 -> v4.4.0. string
+// Parse returns the remote repos of the pre-commit configuration in data.
+// The local and meta repos are skipped.
+func Parse(data []byte) ([]Repo, error) {
+	var cfg config
 
-		if line == "" || strings.Contains(line, "already up to date") {
+	err := yaml.Unmarshal(data, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse pre-commit config: %w", err)
+	}
+
+	var ret []Repo
+
+	for _, repo := range cfg.Repos {
+		if repo.Repo.Value == "local" || repo.Repo.Value == "meta" || repo.Rev.Value == "" {
 			continue
 		}
 
-		fields := strings.Fields(line)
-		if fields[0] != "Updating" {
-			continue
+		r := Repo{
+			URL:  repo.Repo.Value,
+			Rev:  repo.Rev.Value,
+			Line: repo.Rev.Line,
+		}
+
+		if matches := frozenRegex.FindStringSubmatch(repo.Rev.LineComment); matches != nil {
+			r.Frozen = matches[1]
+		}
+
+		ret = append(ret, r)
+	}
+
+	return ret, nil
+}
+
+var (
+	revRegex           = regexp.MustCompile(`^(\s*-?\s*rev:\s*["']?)([^\s"'#]+)(["']?)(.*)$`)
+	frozenCommentRegex = regexp.MustCompile(`\s*#\s*frozen:\s*\S+`)
+)
+
+// SetRev rewrites the rev of repo in data from from to to, leaving the rest
+// of data intact. When frozen is set, to is a commit and a "# frozen:"
+// comment with the version is written after it. An existing "# frozen:"
+// comment is dropped otherwise.
+func SetRev(data []byte, repo, from, to, frozen string) ([]byte, error) {
+	repos, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	line := 0
+
+	for _, r := range repos {
+		if r.URL == repo {
+			line = r.Line
+
+			break
 		}
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if line == 0 || line > len(lines) {
+		return nil, fmt.Errorf("cannot find rev of %s", repo)
+	}
+
+	matches := revRegex.FindStringSubmatch(lines[line-1])
+	if matches == nil {
+		return nil, fmt.Errorf("cannot find rev of %s in line %d", repo, line)
+	}
 
-		changes = append(changes, tool.Change{
-			Repo:       fields[1],
-			Version:    strings.Trim(fields[6], "."),
-			OldVersion: fields[4],
-		})
+	if matches[2] != from {
+		return nil, fmt.Errorf("rev of %s is %s, expected %s", repo, matches[2], from)
 	}
 
-	return changes
+	rest := frozenCommentRegex.ReplaceAllString(matches[4], "")
+
+	if frozen != "" {
+		rest = "  # frozen: " + frozen + rest
+	}
+
+	lines[line-1] = matches[1] + to + matches[3] + rest
+
+	return []byte(strings.Join(lines, "\n")), nil
 }
@@ -0,0 +1,125 @@
+package precommit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var sample = `repos:
+  # hooks
+  - repo: https://github.com/pre-commit/pre-commit-hooks
+    rev: v4.3.0 # keep
+    hooks:
+      - id: trailing-whitespace
+  - repo: "https://github.com/psf/black"
+    rev: "22.10.0"
+    hooks:
+      - id: black
+  - repo: https://github.com/golangci/golangci-lint
+    rev: 0123456789abcdef0123456789abcdef01234567  # frozen: v1.50.0
+    hooks:
+      - id: golangci-lint
+  - repo: local
+    hooks:
+      - id: test
+`
+
+func TestParse(t *testing.T) {
+	repos, err := Parse([]byte(sample))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Repo{
+		{URL: "https://github.com/pre-commit/pre-commit-hooks", Rev: "v4.3.0", Line: 4},
+		{URL: "https://github.com/psf/black", Rev: "22.10.0", Line: 8},
+		{URL: "https://github.com/golangci/golangci-lint", Rev: "0123456789abcdef0123456789abcdef01234567", Frozen: "v1.50.0", Line: 12},
+	}, repos)
+
+	assert.Equal(t, "v1.50.0", repos[2].Version().Original())
+}
+
+func TestSetRev(t *testing.T) {
+	cases := []struct {
+		name   string
+		repo   string
+		from   string
+		to     string
+		frozen string
+		want   string
+	}{
+		{
+			name: "keeps comments",
+			repo: "https://github.com/pre-commit/pre-commit-hooks",
+			from: "v4.3.0",
+			to:   "v4.4.0",
+			want: "    rev: v4.4.0 # keep",
+		},
+		{
+			name: "keeps quotes",
+			repo: "https://github.com/psf/black",
+			from: "22.10.0",
+			to:   "23.1.0",
+			want: `    rev: "23.1.0"`,
+		},
+		{
+			name:   "freeze",
+			repo:   "https://github.com/pre-commit/pre-commit-hooks",
+			from:   "v4.3.0",
+			to:     "fedcba9876543210fedcba9876543210fedcba98",
+			frozen: "v4.4.0",
+			want:   "    rev: fedcba9876543210fedcba9876543210fedcba98  # frozen: v4.4.0 # keep",
+		},
+		{
+			name:   "refreeze",
+			repo:   "https://github.com/golangci/golangci-lint",
+			from:   "0123456789abcdef0123456789abcdef01234567",
+			to:     "fedcba9876543210fedcba9876543210fedcba98",
+			frozen: "v1.51.0",
+			want:   "    rev: fedcba9876543210fedcba9876543210fedcba98  # frozen: v1.51.0",
+		},
+		{
+			name: "unfreeze",
+			repo: "https://github.com/golangci/golangci-lint",
+			from: "0123456789abcdef0123456789abcdef01234567",
+			to:   "v1.51.0",
+			want: "    rev: v1.51.0",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := SetRev([]byte(sample), test.repo, test.from, test.to, test.frozen)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			repos, err := Parse(data)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			for _, repo := range repos {
+				if repo.URL != test.repo {
+					continue
+				}
+
+				assert.Equal(t, test.want, lines(data)[repo.Line-1], test.name)
+			}
+
+			assert.Equal(t, len(lines([]byte(sample))), len(lines(data)), test.name)
+		})
+	}
+
+	_, err := SetRev([]byte(sample), "https://github.com/psf/black", "v1.0.0", "v23.1.0", "")
+	assert.NotNil(t, err)
+
+	_, err = SetRev([]byte(sample), "https://github.com/missing/repo", "v1.0.0", "v23.1.0", "")
+	assert.NotNil(t, err)
+}
+
+func lines(data []byte) []string {
+	return strings.Split(string(data), "\n")
+}
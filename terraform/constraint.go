@@ -0,0 +1,220 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Constraint is a Terraform version constraint such as "1.2.3", "~> 5.0" or
+// ">= 3.1, < 4.0".
+type Constraint struct {
+	raw   string
+	parts []constraintPart
+}
+
+type constraintPart struct {
+	op        string
+	version   *semver.Version
+	text      string
+	precision int
+	start     int
+	end       int
+}
+
+var constraintRegex = regexp.MustCompile(`^(\s*)(!=|>=|<=|~>|=|>|<)?(\s*)(v?\d+(?:\.\d+){0,2}(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)\s*$`)
+
+// ParseConstraint parses the Terraform constraint syntax. A bare version is
+// an exact constraint.
+func ParseConstraint(raw string) (*Constraint, error) {
+	ret := Constraint{raw: raw}
+
+	offset := 0
+
+	for _, part := range strings.Split(raw, ",") {
+		matches := constraintRegex.FindStringSubmatch(part)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid constraint %q", raw)
+		}
+
+		version, err := semver.NewVersion(matches[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid version in constraint %q: %w", raw, err)
+		}
+
+		start := offset + len(matches[1]) + len(matches[2]) + len(matches[3])
+
+		ret.parts = append(ret.parts, constraintPart{
+			op:        matches[2],
+			version:   version,
+			text:      matches[4],
+			precision: precision(matches[4]),
+			start:     start,
+			end:       start + len(matches[4]),
+		})
+
+		offset += len(part) + 1
+	}
+
+	return &ret, nil
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Version returns the version of the first part of the constraint.
+func (c *Constraint) Version() *semver.Version {
+	return c.parts[0].version
+}
+
+// Check reports whether v satisfies every part of the constraint. Like
+// Terraform, pre-releases are only matched by parts that name a pre-release.
+func (c *Constraint) Check(v *semver.Version) bool {
+	for _, part := range c.parts {
+		if !part.check(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p constraintPart) check(v *semver.Version) bool {
+	if v.Prerelease() != "" && p.version.Prerelease() == "" {
+		return false
+	}
+
+	switch p.op {
+	case "", "=":
+		return v.Equal(p.version)
+	case "!=":
+		return !v.Equal(p.version)
+	case ">":
+		return v.GreaterThan(p.version)
+	case ">=":
+		return !v.LessThan(p.version)
+	case "<":
+		return v.LessThan(p.version)
+	case "<=":
+		return !v.GreaterThan(p.version)
+	case "~>":
+		if v.LessThan(p.version) {
+			return false
+		}
+
+		prefix := segments(p.version)[:p.precision-1]
+		for i, segment := range prefix {
+			if segments(v)[i] != segment {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// Allow returns a copy of the constraint rewritten so that it accepts v,
+// keeping the operators, spacing and precision of the original.
+func (c *Constraint) Allow(v *semver.Version) (*Constraint, error) {
+	raw := c.raw
+
+	for i := len(c.parts) - 1; i >= 0; i-- {
+		part := c.parts[i]
+		if part.check(v) {
+			continue
+		}
+
+		var text string
+
+		switch part.op {
+		case "", "=", "<=":
+			text = format(segments(v), part.precision, v)
+		case "~>":
+			next := segments(v)
+			for j := part.precision - 1; j < len(next); j++ {
+				next[j] = 0
+			}
+
+			text = format(next, part.precision, nil)
+		case "<":
+			bound := segments(part.version)
+
+			significant := 0
+			for j := 0; j < part.precision; j++ {
+				if bound[j] != 0 {
+					significant = j
+				}
+			}
+
+			next := segments(v)
+			next[significant]++
+
+			for j := significant + 1; j < len(next); j++ {
+				next[j] = 0
+			}
+
+			text = format(next, part.precision, nil)
+		default:
+			return nil, fmt.Errorf("cannot rewrite %q of %q to allow %s", part.op, c.raw, v)
+		}
+
+		if strings.HasPrefix(part.text, "v") {
+			text = "v" + text
+		}
+
+		raw = raw[:part.start] + text + raw[part.end:]
+	}
+
+	ret, err := ParseConstraint(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ret.Check(v) {
+		return nil, fmt.Errorf("cannot rewrite %q to allow %s", c.raw, v)
+	}
+
+	return ret, nil
+}
+
+func precision(version string) int {
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+
+	return strings.Count(core, ".") + 1
+}
+
+func segments(v *semver.Version) []uint64 {
+	return []uint64{v.Major(), v.Minor(), v.Patch()}
+}
+
+// format renders the first n segments. If that would lose part of exact,
+// the full version is rendered instead.
+func format(segments []uint64, n int, exact *semver.Version) string {
+	if exact != nil {
+		for i := n; i < len(segments); i++ {
+			if segments[i] != 0 {
+				n = len(segments)
+			}
+		}
+
+		if exact.Prerelease() != "" || exact.Metadata() != "" {
+			return exact.String()
+		}
+	}
+
+	fields := make([]string, n)
+	for i := 0; i < n; i++ {
+		fields[i] = strconv.FormatUint(segments[i], 10)
+	}
+
+	return strings.Join(fields, ".")
+}
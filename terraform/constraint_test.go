@@ -0,0 +1,88 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	cases := []struct {
+		name       string
+		constraint string
+		version    string
+		want       bool
+	}{
+		{name: "exact", constraint: "1.2.3", version: "1.2.3", want: true},
+		{name: "exact newer", constraint: "= 1.2.3", version: "1.2.4"},
+		{name: "pessimistic minor", constraint: "~> 5.0", version: "5.31.0", want: true},
+		{name: "pessimistic minor next major", constraint: "~> 5.0", version: "6.0.0"},
+		{name: "pessimistic patch", constraint: "~> 5.1.0", version: "5.1.9", want: true},
+		{name: "pessimistic patch next minor", constraint: "~> 5.1.0", version: "5.2.0"},
+		{name: "range", constraint: ">= 3.1, < 4.0", version: "3.9.9", want: true},
+		{name: "range upper", constraint: ">= 3.1, < 4.0", version: "4.0.0"},
+		{name: "excluded", constraint: ">= 3.1, != 3.2.0", version: "3.2.0"},
+		{name: "pre-release needs an exact pre-release", constraint: ">= 1.0", version: "2.0.0-beta1"},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			constraint, err := ParseConstraint(test.constraint)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			assert.Equal(t, test.want, constraint.Check(semver.MustParse(test.version)), test.name)
+		})
+	}
+}
+
+func TestConstraintAllow(t *testing.T) {
+	cases := []struct {
+		name       string
+		constraint string
+		version    string
+		want       string
+		err        bool
+	}{
+		{name: "exact", constraint: "6.1.2", version: "6.2.0", want: "6.2.0"},
+		{name: "exact with operator", constraint: "= 3.4.0", version: "3.5.1", want: "= 3.5.1"},
+		{name: "exact with v prefix", constraint: "v1.0.0", version: "1.1.0", want: "v1.1.0"},
+		{name: "pessimistic minor", constraint: "~> 5.0", version: "6.2.1", want: "~> 6.0"},
+		{name: "pessimistic minor without space", constraint: "~>5.12", version: "6.2.1", want: "~>6.0"},
+		{name: "pessimistic patch", constraint: "~> 5.1.0", version: "5.3.2", want: "~> 5.3.0"},
+		{name: "range", constraint: ">= 3.1, < 4.0", version: "4.2.0", want: ">= 3.1, < 5.0"},
+		{name: "range on minor", constraint: ">= 3.1.0, < 3.2.0", version: "3.4.1", want: ">= 3.1.0, < 3.5.0"},
+		{name: "inclusive upper bound", constraint: ">= 1.0, <= 1.4", version: "1.6.0", want: ">= 1.0, <= 1.6"},
+		{name: "inclusive upper bound with patch", constraint: "<= 1.4", version: "1.6.3", want: "<= 1.6.3"},
+		{name: "excluded version", constraint: "!= 1.2.0", version: "1.2.0", err: true},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			constraint, err := ParseConstraint(test.constraint)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			allowed, err := constraint.Allow(semver.MustParse(test.version))
+			if test.err {
+				assert.NotNil(t, err, test.name)
+
+				return
+			}
+
+			if assert.Nil(t, err, test.name) {
+				assert.Equal(t, test.want, allowed.String(), test.name)
+			}
+		})
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	for _, constraint := range []string{"", "latest", "~> ", ">= 1.0,"} {
+		_, err := ParseConstraint(constraint)
+		assert.NotNil(t, err, constraint)
+	}
+}
@@ -2,29 +2,33 @@ package terraform
 
 import (
 	"encoding/json"
-	"io"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	log "github.com/sirupsen/logrus"
 )
 
-func RegistryVersions(module string) ([]*semver.Version, string) {
-	url := "https://registry.terraform.io/v1/modules/" + module
-	resp, err := http.Get(url)
-	if err != nil {
-		panic(err)
-	}
+// RegistryURL is the registry queried for sources without a hostname.
+var RegistryURL = "https://registry.terraform.io"
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		panic(err)
+// RegistryVersions returns the published versions of a registry module
+// source such as terraform-aws-modules/vpc/aws, and the URL of the module's
+// source repository. Sources of any other kind, like local paths and git
+// repositories, have no registry versions.
+func RegistryVersions(module string) ([]*semver.Version, string, error) {
+	if strings.Contains(module, "::") || strings.Contains(module, "?") || len(strings.Split(module, "/")) != 3 {
+		return nil, "", fmt.Errorf("%s is not a registry module", module)
 	}
 
+	url := RegistryURL + "/v1/modules/" + module
+
 	var mod TerraformRegistryModuleResponse
-	err = json.Unmarshal(body, &mod)
+
+	err := get(url, &mod)
 	if err != nil {
-		panic(err)
+		return nil, "", err
 	}
 
 	log.WithFields(log.Fields{
@@ -34,10 +38,15 @@ func RegistryVersions(module string) ([]*semver.Version, string) {
 	var ret []*semver.Version
 
 	for _, version := range mod.Versions {
-
 		semVersion, err := semver.NewVersion(version)
 		if err != nil {
-			panic(err)
+			log.WithFields(log.Fields{
+				"module":  module,
+				"version": version,
+				"error":   err,
+			}).Debug("cannot parse module version")
+
+			continue
 		}
 
 		log.WithFields(log.Fields{
@@ -53,7 +62,107 @@ func RegistryVersions(module string) ([]*semver.Version, string) {
 		"len":    len(ret),
 	}).Debug("Versions")
 
-	return ret, mod.Source
+	return ret, mod.Source, nil
+}
+
+// ProviderVersions returns the published versions of a provider source such
+// as hashicorp/aws or registry.terraform.io/hashicorp/aws, and the URL of the
+// provider's source repository.
+func ProviderVersions(provider string) ([]*semver.Version, string, error) {
+	registry := RegistryURL
+
+	fields := strings.Split(provider, "/")
+	switch len(fields) {
+	case 2:
+	case 3:
+		registry = "https://" + fields[0]
+		fields = fields[1:]
+	default:
+		return nil, "", fmt.Errorf("invalid provider source %s", provider)
+	}
+
+	url := fmt.Sprintf("%s/v1/providers/%s/%s", registry, fields[0], fields[1])
+
+	var details TerraformRegistryProviderResponse
+
+	err := get(url, &details)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var versions TerraformRegistryProviderVersionsResponse
+
+	err = get(url+"/versions", &versions)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var ret []*semver.Version
+
+	for _, version := range versions.Versions {
+		semVersion, err := semver.NewVersion(version.Version)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"provider": provider,
+				"version":  version.Version,
+				"error":    err,
+			}).Debug("cannot parse provider version")
+
+			continue
+		}
+
+		ret = append(ret, semVersion)
+	}
+
+	log.WithFields(log.Fields{
+		"provider": provider,
+		"source":   details.Source,
+		"len":      len(ret),
+	}).Debug("Versions")
+
+	return ret, details.Source, nil
+}
+
+func get(url string, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("cannot get %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get %s: %s", url, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("cannot decode %s: %w", url, err)
+	}
+
+	return nil
+}
+
+type TerraformRegistryProviderResponse struct {
+	ID          string   `json:"id"`
+	Namespace   string   `json:"namespace"`
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Source      string   `json:"source"`
+	Description string   `json:"description"`
+	PublishedAt string   `json:"published_at"`
+	Versions    []string `json:"versions"`
+}
+
+type TerraformRegistryProviderVersionsResponse struct {
+	ID       string `json:"id"`
+	Versions []struct {
+		Version   string   `json:"version"`
+		Protocols []string `json:"protocols"`
+		Platforms []struct {
+			Os   string `json:"os"`
+			Arch string `json:"arch"`
+		} `json:"platforms"`
+	} `json:"versions"`
 }
 
 type TerraformRegistryModuleResponse struct {
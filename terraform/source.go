@@ -0,0 +1,103 @@
+package terraform
+
+import (
+	"net/url"
+	"strings"
+)
+
+// GitSource is a module source fetched from a git repository at a ref, such
+// as git::https://example.com/vpc.git//modules/subnet?ref=v1.2.3.
+type GitSource struct {
+	// Remote is the URL of the repository, without the subdirectory and the
+	// query.
+	Remote string
+	Ref    string
+}
+
+// ParseGitSource returns the git repository and ref of a module source.
+// Sources forced with git::, github.com/org/repo and git@host:org/repo
+// shorthands are git sources. Sources without a ref follow the default
+// branch and have nothing to update.
+func ParseGitSource(source string) (GitSource, bool) {
+	var ret GitSource
+
+	address, query, _ := strings.Cut(source, "?")
+
+	switch {
+	case strings.HasPrefix(address, "git::"):
+		address = strings.TrimPrefix(address, "git::")
+	case strings.HasPrefix(address, "github.com/"):
+		address = "https://" + address
+	case strings.HasPrefix(address, "git@"):
+	default:
+		return ret, false
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil || values.Get("ref") == "" {
+		return ret, false
+	}
+
+	// The subdirectory starts at the first // after the scheme.
+	scheme := 0
+	if i := strings.Index(address, "://"); i >= 0 {
+		scheme = i + len("://")
+	}
+
+	if i := strings.Index(address[scheme:], "//"); i >= 0 {
+		address = address[:scheme+i]
+	}
+
+	ret.Remote = address
+	ret.Ref = values.Get("ref")
+
+	return ret, true
+}
+
+// GitHub returns the https://github.com/owner/repo URL of the repository, or
+// an empty string for repositories that are not on GitHub.
+func (g GitSource) GitHub() string {
+	path := ""
+
+	switch {
+	case strings.HasPrefix(g.Remote, "https://github.com/"):
+		path = strings.TrimPrefix(g.Remote, "https://github.com/")
+	case strings.HasPrefix(g.Remote, "ssh://git@github.com/"):
+		path = strings.TrimPrefix(g.Remote, "ssh://git@github.com/")
+	case strings.HasPrefix(g.Remote, "git@github.com:"):
+		path = strings.TrimPrefix(g.Remote, "git@github.com:")
+	default:
+		return ""
+	}
+
+	fields := strings.Split(strings.TrimSuffix(path, ".git"), "/")
+	if len(fields) != 2 {
+		return ""
+	}
+
+	return "https://github.com/" + fields[0] + "/" + fields[1]
+}
+
+// SetRef returns source with the value of its ref query parameter set to
+// ref. The rest of the source, including the other parameters, is kept as
+// written.
+func SetRef(source, ref string) string {
+	i := strings.Index(source, "?")
+	if i < 0 {
+		return source
+	}
+
+	offset := i + 1
+
+	for _, param := range strings.Split(source[offset:], "&") {
+		if strings.HasPrefix(param, "ref=") {
+			start := offset + len("ref=")
+
+			return source[:start] + url.QueryEscape(ref) + source[offset+len(param):]
+		}
+
+		offset += len(param) + 1
+	}
+
+	return source
+}
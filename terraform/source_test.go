@@ -0,0 +1,99 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitSource(t *testing.T) {
+	cases := []struct {
+		name   string
+		source string
+		want   GitSource
+		github string
+		ok     bool
+	}{
+		{
+			name:   "git over https with a subdirectory",
+			source: "git::https://github.com/org/repo.git//modules/vpc?ref=v1.2.3",
+			want:   GitSource{Remote: "https://github.com/org/repo.git", Ref: "v1.2.3"},
+			github: "https://github.com/org/repo",
+			ok:     true,
+		},
+		{
+			name:   "github shorthand",
+			source: "github.com/org/repo?ref=1.0.0",
+			want:   GitSource{Remote: "https://github.com/org/repo", Ref: "1.0.0"},
+			github: "https://github.com/org/repo",
+			ok:     true,
+		},
+		{
+			name:   "git over ssh",
+			source: "git::ssh://git@gitlab.example.com/group/repo.git?depth=1&ref=v2.0.0",
+			want:   GitSource{Remote: "ssh://git@gitlab.example.com/group/repo.git", Ref: "v2.0.0"},
+			ok:     true,
+		},
+		{
+			name:   "scp-like github",
+			source: "git@github.com:org/repo.git//modules/eks?ref=v3.1.0",
+			want:   GitSource{Remote: "git@github.com:org/repo.git", Ref: "v3.1.0"},
+			github: "https://github.com/org/repo",
+			ok:     true,
+		},
+		{
+			name:   "local repository",
+			source: "git::file:///srv/git/modules.git//vpc?ref=v1.0.0",
+			want:   GitSource{Remote: "file:///srv/git/modules.git", Ref: "v1.0.0"},
+			ok:     true,
+		},
+		{name: "default branch", source: "git::https://example.com/vpc.git"},
+		{name: "registry", source: "terraform-aws-modules/vpc/aws"},
+		{name: "local path", source: "./modules/vpc"},
+		{name: "s3", source: "s3::https://s3.amazonaws.com/bucket/vpc.zip?ref=v1.0.0"},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := ParseGitSource(test.source)
+
+			assert.Equal(t, test.ok, ok, test.name)
+			assert.Equal(t, test.want, got, test.name)
+			assert.Equal(t, test.github, got.GitHub(), test.name)
+		})
+	}
+}
+
+func TestSetRef(t *testing.T) {
+	cases := []struct {
+		name   string
+		source string
+		ref    string
+		want   string
+	}{
+		{
+			name:   "only ref",
+			source: "git::https://github.com/org/repo.git//modules/vpc?ref=v1.2.3",
+			ref:    "v1.3.0",
+			want:   "git::https://github.com/org/repo.git//modules/vpc?ref=v1.3.0",
+		},
+		{
+			name:   "other parameters are kept",
+			source: "git::ssh://git@example.com/repo.git?depth=1&ref=v1.2.3&sshkey=a%2Bb",
+			ref:    "v2.0.0",
+			want:   "git::ssh://git@example.com/repo.git?depth=1&ref=v2.0.0&sshkey=a%2Bb",
+		},
+		{
+			name:   "no ref",
+			source: "git::https://example.com/vpc.git?depth=1",
+			ref:    "v2.0.0",
+			want:   "git::https://example.com/vpc.git?depth=1",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, SetRef(test.source, test.ref), test.name)
+		})
+	}
+}
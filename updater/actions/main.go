@@ -0,0 +1,213 @@
+package actions
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	gh "github.com/google/go-github/v50/github"
+	"github.com/mhristof/bump/updater"
+	"github.com/mhristof/bump/updater/github"
+)
+
+func init() {
+	updater.Register("actions", 250, func(updater.Options) updater.Updater {
+		return New(nil)
+	})
+}
+
+// IsWorkflow reports whether path is a GitHub Actions workflow or the
+// metadata file of an action.
+func IsWorkflow(path string) bool {
+	path = filepath.ToSlash(path)
+	ext := filepath.Ext(path)
+
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+
+	base := filepath.Base(path)
+
+	return strings.Contains(path, ".github/workflows/") || base == "action.yml" || base == "action.yaml"
+}
+
+// usesRegex matches the owner, repository and ref of a uses: step, with an
+// optional comment after it. Local actions and docker:// images have no
+// owner/repository@ref.
+var (
+	usesRegex    = regexp.MustCompile(`^\s*-?\s*uses:\s*["']?([\w.-]+)/([\w.-]+)(?:/[^@\s"']*)?@([^\s"'#]+)["']?\s*(#.*)?$`)
+	shaRegex     = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	commentRegex = regexp.MustCompile(`#\s*(v?\d+(?:\.\d+){0,2}\S*)`)
+)
+
+// IsUses reports whether line is a uses: step of a remote action.
+func IsUses(line string) bool {
+	return usesRegex.MatchString(line)
+}
+
+// Actions updates the actions that workflows use. Actions pinned to a
+// commit are updated to the commit of the new tag, with the version in the
+// comment that follows.
+type Actions struct {
+	once   sync.Once
+	client *gh.Client
+
+	mu      sync.Mutex
+	commits map[string]map[string]string
+}
+
+// New returns an updater that lists tags with client, or with the client of
+// the github updater when it is nil.
+func New(client *gh.Client) *Actions {
+	return &Actions{
+		client:  client,
+		commits: map[string]map[string]string{},
+	}
+}
+
+type uses struct {
+	owner, repo string
+	ref         string
+	// tag is the ref, or the version of the comment of a commit.
+	tag    string
+	commit bool
+}
+
+func parse(line string) (uses, bool) {
+	matches := usesRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return uses{}, false
+	}
+
+	ret := uses{
+		owner: matches[1],
+		repo:  matches[2],
+		ref:   matches[3],
+		tag:   matches[3],
+	}
+
+	if shaRegex.MatchString(ret.ref) {
+		ret.commit = true
+		ret.tag = ""
+
+		if comment := commentRegex.FindStringSubmatch(matches[4]); comment != nil {
+			ret.tag = comment[1]
+		}
+	}
+
+	return ret, true
+}
+
+func (a *Actions) Scan(file string) bool {
+	return IsWorkflow(file)
+}
+
+func (a *Actions) Match(file, line string) bool {
+	return IsWorkflow(file) && IsUses(line)
+}
+
+// Versions returns the tags of the action that are written like the current
+// one, so actions used by major version, like @v4, stay on major versions.
+func (a *Actions) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	u, ok := parse(line)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot find action in %s", line)
+	}
+
+	if u.tag == "" {
+		return nil, nil, fmt.Errorf("cannot find the version of %s/%s@%s", u.owner, u.repo, u.ref)
+	}
+
+	current, variant, err := updater.SplitTag(u.tag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a.once.Do(func() {
+		if a.client == nil {
+			a.client = github.Client()
+		}
+	})
+
+	tags, err := github.Tags(a.client, u.owner, u.repo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(tags))
+	commits := map[string]string{}
+
+	for _, tag := range tags {
+		names = append(names, tag.GetName())
+		commits[tag.GetName()] = tag.GetCommit().GetSHA()
+	}
+
+	a.mu.Lock()
+	a.commits[u.owner+"/"+u.repo] = commits
+	a.mu.Unlock()
+
+	var ret []*semver.Version
+
+	for _, version := range updater.TagVersions(names, variant) {
+		if precision(version) == precision(current) {
+			ret = append(ret, version)
+		}
+	}
+
+	return current, ret, nil
+}
+
+// precision returns how many parts of the version are written, 1 for v4 and
+// 3 for v4.1.0.
+func precision(v *semver.Version) int {
+	version, _, _ := strings.Cut(v.Original(), "-")
+
+	return strings.Count(version, ".") + 1
+}
+
+func (a *Actions) Repository(line string) string {
+	u, ok := parse(line)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("https://github.com/%s/%s", u.owner, u.repo)
+}
+
+// Rewrite sets the ref of the action to next. Actions pinned to a commit get
+// the commit of next and next in their comment, and are left alone when the
+// commit of next is not known.
+func (a *Actions) Rewrite(line string, current, next *semver.Version) string {
+	u, ok := parse(line)
+	if !ok {
+		return line
+	}
+
+	_, variant, err := updater.SplitTag(u.tag)
+	if err != nil {
+		return line
+	}
+
+	tag := next.Original() + variant
+
+	if !u.commit {
+		return strings.Replace(line, "@"+u.ref, "@"+tag, 1)
+	}
+
+	a.mu.Lock()
+	commit := a.commits[u.owner+"/"+u.repo][tag]
+	a.mu.Unlock()
+
+	if commit == "" {
+		return line
+	}
+
+	i := strings.Index(line, "@"+u.ref)
+	comment := strings.Index(line[i:], "#") + i
+
+	return line[:i] + "@" + commit + line[i+1+len(u.ref):comment] +
+		strings.Replace(line[comment:], u.tag, tag, 1)
+}
@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	gh "github.com/google/go-github/v50/github"
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func sha(c string) string {
+	return strings.Repeat(c, 40)
+}
+
+// server serves the tags of actions/checkout in two pages.
+func server(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/actions/checkout/tags" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/actions/checkout/tags?page=2&per_page=100>; rel="next"`, srv.URL))
+			fmt.Fprintf(w, `[{"name": "v4", "commit": {"sha": "%s"}}, {"name": "v4.1.1", "commit": {"sha": "%s"}}]`, sha("a"), sha("a"))
+
+			return
+		}
+
+		fmt.Fprintf(w, `[{"name": "v4.1.0", "commit": {"sha": "%s"}}, {"name": "v3", "commit": {"sha": "%s"}}, {"name": "v3.6.0", "commit": {"sha": "%s"}}]`, sha("b"), sha("c"), sha("c"))
+	}))
+
+	return srv
+}
+
+func TestActions(t *testing.T) {
+	srv := server(t)
+	defer srv.Close()
+
+	client := gh.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	cases := []struct {
+		name    string
+		line    string
+		newLine string
+	}{
+		{
+			name:    "major version",
+			line:    "      - uses: actions/checkout@v3",
+			newLine: "      - uses: actions/checkout@v4",
+		},
+		{
+			name:    "full version",
+			line:    "      - uses: actions/checkout@v3.6.0",
+			newLine: "      - uses: actions/checkout@v4.1.1",
+		},
+		{
+			name:    "pinned to a commit",
+			line:    "      - uses: actions/checkout@" + sha("c") + " # v3.6.0",
+			newLine: "      - uses: actions/checkout@" + sha("a") + " # v4.1.1",
+		},
+		{
+			name:    "quoted",
+			line:    `    uses: "actions/checkout@v3"`,
+			newLine: `    uses: "actions/checkout@v4"`,
+		},
+	}
+
+	a := New(client)
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			if !assert.True(t, a.Match(".github/workflows/ci.yml", test.line), test.name) {
+				return
+			}
+
+			current, versions, err := a.Versions(test.line)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			next := policy.Select(policy.Major, policy.SameTrack, current, versions)
+			if !assert.NotNil(t, next, test.name) {
+				return
+			}
+
+			assert.Equal(t, test.newLine, a.Rewrite(test.line, current, next), test.name)
+		})
+	}
+
+	_, _, err := a.Versions("      - uses: actions/checkout@" + sha("c"))
+	assert.NotNil(t, err)
+}
+
+func TestMatch(t *testing.T) {
+	a := New(nil)
+
+	cases := []struct {
+		file string
+		line string
+		want bool
+	}{
+		{file: ".github/workflows/ci.yml", line: "- uses: actions/setup-go@v4", want: true},
+		{file: "repo/.github/workflows/release.yaml", line: "uses: github/codeql-action/init@v2 # init", want: true},
+		{file: "action.yml", line: "    - uses: actions/cache@v3", want: true},
+		{file: ".github/workflows/ci.yml", line: "- uses: ./.github/actions/local"},
+		{file: ".github/workflows/ci.yml", line: "- uses: docker://alpine:3.18"},
+		{file: "docs/ci.yml", line: "- uses: actions/setup-go@v4"},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.want, a.Match(test.file, test.line), test.line)
+	}
+}
@@ -0,0 +1,136 @@
+package ami
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/awsdata"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	updater.Register("ami", 400, func(opts updater.Options) updater.Updater {
+		return &AMI{
+			threads: opts.Threads,
+			images:  map[string]string{},
+		}
+	})
+}
+
+// AMI updates quoted AMI names to the newest image with the same owner and
+// architecture.
+type AMI struct {
+	threads int
+	once    sync.Once
+	aws     *awsdata.AWS
+
+	mu sync.Mutex
+	// images maps the AMI names looked up to the newest image Versions
+	// found for them.
+	images map[string]string
+}
+
+var versionRegex = regexp.MustCompile(`\d+\.\d+\.\d+`)
+
+func (a *AMI) data() *awsdata.AWS {
+	a.once.Do(func() {
+		a.aws = awsdata.New(a.threads)
+	})
+
+	return a.aws
+}
+
+func (a *AMI) Match(file, line string) bool {
+	return strings.Contains(line, "-ami-")
+}
+
+func amiName(line string) string {
+	fields := strings.Split(line, `"`)
+	if len(fields) < 2 {
+		return ""
+	}
+
+	return fields[1]
+}
+
+func (a *AMI) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	name := amiName(line)
+	if name == "" {
+		return nil, nil, fmt.Errorf("cannot find quoted AMI name in %s", line)
+	}
+
+	// Names without a version, like the date stamped Ubuntu images, are
+	// handled by Replace.
+	version := versionRegex.FindString(name)
+	if version == "" {
+		return nil, nil, nil
+	}
+
+	log.WithFields(log.Fields{
+		"line": line,
+		"name": name,
+	}).Debug("searching for AMI")
+
+	newAMI := a.data().ValidAMI(name)
+
+	a.mu.Lock()
+	a.images[name] = newAMI
+	a.mu.Unlock()
+
+	current, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot find version in AMI %s: %w", name, err)
+	}
+
+	if newAMI == "" {
+		log.WithFields(log.Fields{
+			"line": line,
+			"name": name,
+		}).Trace("no AMI found")
+
+		return current, nil, nil
+	}
+
+	newVersion, err := semver.NewVersion(versionRegex.FindString(newAMI))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot find version in AMI %s: %w", newAMI, err)
+	}
+
+	return current, []*semver.Version{newVersion}, nil
+}
+
+// Rewrite replaces the AMI name in line with the newest image that Versions
+// found for it.
+func (a *AMI) Rewrite(line string, current, next *semver.Version) string {
+	name := amiName(line)
+
+	a.mu.Lock()
+	newAMI, ok := a.images[name]
+	a.mu.Unlock()
+
+	if !ok || newAMI == "" {
+		return line
+	}
+
+	return strings.ReplaceAll(line, name, newAMI)
+}
+
+// Replace replaces the AMI name in line with the newest image with the same
+// owner and architecture, for names that have no version to compare.
+func (a *AMI) Replace(line string) (string, error) {
+	name := amiName(line)
+	if name == "" {
+		return "", fmt.Errorf("cannot find quoted AMI name in %s", line)
+	}
+
+	newAMI := a.data().ValidAMI(name)
+	if newAMI == "" {
+		return line, nil
+	}
+
+	return strings.ReplaceAll(line, name, newAMI), nil
+}
@@ -0,0 +1,289 @@
+package dockerhub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/updater"
+	"github.com/mhristof/bump/updater/oci"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultURL = "https://hub.docker.com"
+	// registryHost serves the manifests of Docker Hub images.
+	registryHost = "registry-1.docker.io"
+)
+
+func init() {
+	updater.Register("dockerhub", 100, func(updater.Options) updater.Updater {
+		return New(defaultURL, os.Getenv("DOCKERHUB_USERNAME"), os.Getenv("DOCKERHUB_TOKEN"))
+	})
+}
+
+// DockerHub updates container images hosted on Docker Hub.
+type DockerHub struct {
+	baseURL  string
+	username string
+	token    string
+	client   *http.Client
+
+	once sync.Once
+	jwt  string
+
+	registryHost string
+	registryOnce sync.Once
+	registry     *oci.Client
+}
+
+// New returns a Docker Hub updater for the API at baseURL. When username
+// and token are set, the requests are authenticated to avoid the anonymous
+// rate limits. token can be a password or a personal access token.
+func New(baseURL, username, token string) *DockerHub {
+	return &DockerHub{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		username:     username,
+		token:        token,
+		client:       http.DefaultClient,
+		registryHost: registryHost,
+	}
+}
+
+var (
+	// hubRegex matches images with an explicit Docker Hub registry, which
+	// may omit the library/ namespace of official images.
+	hubRegex = regexp.MustCompile(`(?:^|[^\w./-])((?:docker\.io|index\.docker\.io|registry-1\.docker\.io)/((?:[\w.-]+/)?[\w.-]+):([^\s"'@]+))`)
+	// keyRegex matches official images in image: keys, like image: alpine:3.18.
+	keyRegex = regexp.MustCompile(`\bimage:\s*["']?(([\w.-]+):([^\s"'@]+))`)
+	// imageRegex matches namespace/repository:tag images.
+	imageRegex = regexp.MustCompile(`(?:^|[^\w./-])(([\w-][\w.-]*/[\w.-]+):([^\s"'@]+))`)
+)
+
+// reference is an image found in a line. image is written in the line and
+// name is the repository on Docker Hub.
+type reference struct {
+	image string
+	name  string
+	tag   string
+}
+
+func find(line string) (reference, bool) {
+	for _, r := range []*regexp.Regexp{hubRegex, keyRegex, imageRegex} {
+		matches := r.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		ret := reference{
+			image: matches[1],
+			name:  matches[2],
+			tag:   matches[3],
+		}
+
+		if !strings.Contains(ret.name, "/") {
+			ret.name = "library/" + ret.name
+		}
+
+		return ret, true
+	}
+
+	return reference{}, false
+}
+
+func (d *DockerHub) Match(file, line string) bool {
+	_, ok := find(line)
+
+	return ok
+}
+
+func (d *DockerHub) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	ref, ok := find(line)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot find docker hub image in %s", line)
+	}
+
+	tagVersion, variant, err := updater.SplitTag(ref.tag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse tag %s of %s: %w", ref.tag, ref.name, err)
+	}
+
+	log.WithFields(log.Fields{
+		"image":   ref.image,
+		"name":    ref.name,
+		"tag":     ref.tag,
+		"variant": variant,
+	}).Debug("dockerHub")
+
+	tags, err := d.tags(ref.name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tagVersion, updater.TagVersions(tags, variant), nil
+}
+
+func (d *DockerHub) Rewrite(line string, current, next *semver.Version) string {
+	ref, ok := find(line)
+	if !ok {
+		return line
+	}
+
+	return updater.ReplaceTag(line, ref.image, next)
+}
+
+// Pin pins the image in line to the digest of its tag, resolved through
+// the registry API of Docker Hub with the credentials of the docker config.
+func (d *DockerHub) Pin(line string) (string, error) {
+	ref, ok := find(line)
+	if !ok {
+		return "", fmt.Errorf("cannot find docker hub image in %s", line)
+	}
+
+	d.registryOnce.Do(func() {
+		if d.registry == nil {
+			d.registry = oci.NewClient(oci.DockerConfig())
+		}
+	})
+
+	digest, err := d.registry.Digest(d.registryHost, ref.name, ref.tag)
+	if err != nil {
+		return "", err
+	}
+
+	return updater.SetDigest(line, ref.image, digest), nil
+}
+
+// tags returns the names of every page of tags of the repository name, most
+// recently updated first.
+func (d *DockerHub) tags(name string) ([]string, error) {
+	var ret []string
+
+	for apiURL := fmt.Sprintf("%s/v2/repositories/%s/tags?page_size=100&ordering=last_updated", d.baseURL, name); apiURL != ""; {
+		req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create request for %s: %w", apiURL, err)
+		}
+
+		if jwt := d.login(); jwt != "" && strings.HasPrefix(apiURL, d.baseURL+"/") {
+			req.Header.Set("Authorization", "Bearer "+jwt)
+		}
+
+		resp, err := d.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get tags of %s: %w", name, err)
+		}
+
+		var tags DockerHubTagsResponse
+
+		err = json.NewDecoder(resp.Body).Decode(&tags)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("cannot get tags of %s: %s", name, resp.Status)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode tags of %s: %w", name, err)
+		}
+
+		for _, result := range tags.Results {
+			ret = append(ret, result.Name)
+		}
+
+		apiURL = tags.Next
+	}
+
+	log.WithFields(log.Fields{
+		"name": name,
+		"len":  len(ret),
+	}).Debug("found docker hub tags")
+
+	return ret, nil
+}
+
+// login returns the token of the Docker Hub session, or an empty string for
+// anonymous requests.
+func (d *DockerHub) login() string {
+	d.once.Do(func() {
+		if d.username == "" || d.token == "" {
+			return
+		}
+
+		body, err := json.Marshal(map[string]string{
+			"username": d.username,
+			"password": d.token,
+		})
+		if err != nil {
+			return
+		}
+
+		resp, err := d.client.Post(d.baseURL+"/v2/users/login", "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.WithField("error", err).Warning("cannot log in to docker hub")
+
+			return
+		}
+		defer resp.Body.Close()
+
+		var login struct {
+			Token string `json:"token"`
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(&login)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			log.WithFields(log.Fields{
+				"status": resp.Status,
+				"error":  err,
+			}).Warning("cannot log in to docker hub")
+
+			return
+		}
+
+		d.jwt = login.Token
+	})
+
+	return d.jwt
+}
+
+type DockerHubTagsResponse struct {
+	Count    int64       `json:"count"`
+	Next     string      `json:"next"`
+	Previous interface{} `json:"previous"`
+	Results  []struct {
+		ContentType string `json:"content_type"`
+		Creator     int64  `json:"creator"`
+		Digest      string `json:"digest"`
+		FullSize    int64  `json:"full_size"`
+		ID          int64  `json:"id"`
+		Images      []struct {
+			Architecture string      `json:"architecture"`
+			Digest       string      `json:"digest"`
+			Features     string      `json:"features"`
+			LastPulled   string      `json:"last_pulled"`
+			LastPushed   string      `json:"last_pushed"`
+			Os           string      `json:"os"`
+			OsFeatures   string      `json:"os_features"`
+			OsVersion    interface{} `json:"os_version"`
+			Size         int64       `json:"size"`
+			Status       string      `json:"status"`
+			Variant      interface{} `json:"variant"`
+		} `json:"images"`
+		LastUpdated         string `json:"last_updated"`
+		LastUpdater         int64  `json:"last_updater"`
+		LastUpdaterUsername string `json:"last_updater_username"`
+		MediaType           string `json:"media_type"`
+		Name                string `json:"name"`
+		Repository          int64  `json:"repository"`
+		TagLastPulled       string `json:"tag_last_pulled"`
+		TagLastPushed       string `json:"tag_last_pushed"`
+		TagStatus           string `json:"tag_status"`
+		V2                  bool   `json:"v2"`
+	} `json:"results"`
+}
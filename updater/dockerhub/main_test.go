@@ -0,0 +1,160 @@
+package dockerhub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerHub(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		want  string
+	}{
+		{
+			name:  "valid docker image without https:// prefix",
+			image: "prom/alertmanager:v0.25.0",
+			want:  "^prom/alertmanager:v.*$",
+		},
+		{
+			name:  "string with quotes",
+			image: `prom/alertmanager:v0.25.0"`,
+			want:  `prom/alertmanager:v.*"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(defaultURL, "", "")
+
+			current, versions, err := d.Versions(tt.image)
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			next := policy.Select(policy.Major, policy.SameTrack, current, versions)
+			if next == nil {
+				next = current
+			}
+
+			assert.Regexp(t, regexp.MustCompile(tt.want), d.Rewrite(tt.image, current, next))
+		})
+	}
+}
+
+func server(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/users/login" {
+			var body map[string]string
+
+			err := json.NewDecoder(r.Body).Decode(&body)
+			if err != nil || body["username"] != "user" || body["password"] != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			fmt.Fprint(w, `{"token": "jwt"}`)
+
+			return
+		}
+
+		assert.Equal(t, "Bearer jwt", r.Header.Get("Authorization"))
+
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/v2/repositories/library/alpine/tags?page_size=100&ordering=last_updated":
+			fmt.Fprintf(w, `{"next": "%s/v2/repositories/library/alpine/tags?page=2", "results": [{"name": "latest"}, {"name": "3.18"}, {"name": "3.19-rc"}]}`, srv.URL)
+		case "/v2/repositories/library/alpine/tags?page=2":
+			fmt.Fprint(w, `{"next": null, "results": [{"name": "3.19"}, {"name": "3.17"}]}`)
+		case "/v2/repositories/bitnami/redis/tags?page_size=100&ordering=last_updated":
+			fmt.Fprint(w, `{"results": [{"name": "7.2.1-debian-11"}, {"name": "7.2.1"}, {"name": "7.0.11"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return srv
+}
+
+func TestDockerHubAPI(t *testing.T) {
+	srv := server(t)
+	defer srv.Close()
+
+	cases := []struct {
+		name    string
+		line    string
+		newLine string
+	}{
+		{
+			name:    "official image on the second page",
+			line:    "library/alpine:3.18",
+			newLine: "library/alpine:3.19",
+		},
+		{
+			name:    "docker.io without namespace",
+			line:    `image = "docker.io/alpine:3.18"`,
+			newLine: `image = "docker.io/alpine:3.19"`,
+		},
+		{
+			name:    "index.docker.io",
+			line:    "index.docker.io/library/alpine:3.18",
+			newLine: "index.docker.io/library/alpine:3.19",
+		},
+		{
+			name:    "image key without namespace",
+			line:    "    image: alpine:3.18",
+			newLine: "    image: alpine:3.19",
+		},
+		{
+			name:    "namespace",
+			line:    "  image: 'bitnami/redis:7.0.11'",
+			newLine: "  image: 'bitnami/redis:7.2.1'",
+		},
+	}
+
+	d := New(srv.URL, "user", "secret")
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			if !assert.True(t, d.Match("", test.line), test.name) {
+				return
+			}
+
+			current, versions, err := d.Versions(test.line)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			next := policy.Select(policy.Major, policy.SameTrack, current, versions)
+			if !assert.NotNil(t, next, test.name) {
+				return
+			}
+
+			assert.Equal(t, test.newLine, d.Rewrite(test.line, current, next), test.name)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	d := New(defaultURL, "", "")
+
+	for line, want := range map[string]bool{
+		"FROM library/alpine:3.18":           true,
+		"docker.io/alpine:3.18":              true,
+		"image: python:3.11-slim":            true,
+		"quay.io/prometheus/prometheus:v2.0": false,
+		"ghcr.io/org/app:v1.2.3":             false,
+		"alpine:3.18":                        false,
+	} {
+		assert.Equal(t, want, d.Match("", line), line)
+	}
+}
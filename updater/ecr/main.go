@@ -0,0 +1,84 @@
+package ecr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/awsdata"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	updater.Register("ecr", 600, func(opts updater.Options) updater.Updater {
+		return &ECR{threads: opts.Threads}
+	})
+}
+
+// ECR updates container images hosted on AWS ECR.
+type ECR struct {
+	threads int
+	once    sync.Once
+	aws     *awsdata.AWS
+}
+
+var imageRegex = regexp.MustCompile(`(\d*\.dkr\.ecr\.[^/\s]+\.amazonaws\.com/[^\s:"']+):([^\s"'@]+)`)
+
+func (e *ECR) data() *awsdata.AWS {
+	e.once.Do(func() {
+		e.aws = awsdata.New(e.threads)
+	})
+
+	return e.aws
+}
+
+func (e *ECR) Match(file, line string) bool {
+	return strings.Contains(line, "dkr.ecr")
+}
+
+func (e *ECR) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	matches := imageRegex.FindStringSubmatch(line)
+	if len(matches) != 3 {
+		return nil, nil, fmt.Errorf("cannot find ECR repository in %s", line)
+	}
+
+	repoURI := matches[1]
+
+	version, variant, err := updater.SplitTag(matches[2])
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse tag %s of %s: %w", matches[2], repoURI, err)
+	}
+
+	versions := updater.TagVersions(e.data().Tags(repoURI), variant)
+
+	log.WithFields(log.Fields{
+		"repoURI":  repoURI,
+		"version":  version,
+		"versions": versions,
+	}).Debug("Versions")
+
+	return version, versions, nil
+}
+
+func (e *ECR) Rewrite(line string, current, next *semver.Version) string {
+	return updater.ReplaceTag(line, imageRegex.FindString(line), next)
+}
+
+// Pin pins the image in line to the digest of its tag, looked up with the
+// AWS credentials of the account the repository lives in.
+func (e *ECR) Pin(line string) (string, error) {
+	matches := imageRegex.FindStringSubmatch(line)
+	if len(matches) != 3 {
+		return "", fmt.Errorf("cannot find ECR repository in %s", line)
+	}
+
+	digest, err := e.data().Digest(matches[1], matches[2])
+	if err != nil {
+		return "", err
+	}
+
+	return updater.SetDigest(line, matches[0], digest), nil
+}
@@ -0,0 +1,124 @@
+package ghcr
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	gh "github.com/google/go-github/v50/github"
+	"github.com/mhristof/bump/updater"
+	"github.com/mhristof/bump/updater/github"
+	"github.com/mhristof/bump/updater/oci"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	updater.Register("ghcr", 500, func(updater.Options) updater.Updater {
+		return &GHCR{}
+	})
+}
+
+// GHCR updates container images hosted on ghcr.io.
+type GHCR struct {
+	once     sync.Once
+	registry *oci.Client
+}
+
+var imageRegex = regexp.MustCompile(`ghcr.io/([^/]+)/([^/\s:]+):([^\s"'@]+)`)
+
+func (g *GHCR) Match(file, line string) bool {
+	return strings.Contains(line, "ghcr.io")
+}
+
+func (g *GHCR) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	matches := imageRegex.FindStringSubmatch(line)
+	if len(matches) != 4 {
+		return nil, nil, fmt.Errorf("cannot parse ghcr.io line %s", line)
+	}
+
+	org := matches[1]
+	repo := matches[2]
+	tag := matches[3]
+
+	log.WithFields(log.Fields{
+		"line":    line,
+		"matches": matches,
+		"org":     org,
+		"repo":    repo,
+		"tag":     tag,
+	}).Debug("Updating ghcr.io link")
+
+	current, variant, err := updater.SplitTag(tag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse tag %s: %w", tag, err)
+	}
+
+	return current, updater.TagVersions(packageTags(org, repo), variant), nil
+}
+
+func (g *GHCR) Rewrite(line string, current, next *semver.Version) string {
+	return updater.ReplaceTag(line, imageRegex.FindString(line), next)
+}
+
+// Pin pins the image in line to the digest of its tag, resolved through
+// the registry API of ghcr.io with the credentials of the docker config.
+func (g *GHCR) Pin(line string) (string, error) {
+	matches := imageRegex.FindStringSubmatch(line)
+	if len(matches) != 4 {
+		return "", fmt.Errorf("cannot parse ghcr.io line %s", line)
+	}
+
+	g.once.Do(func() {
+		g.registry = oci.NewClient(oci.DockerConfig())
+	})
+
+	digest, err := g.registry.Digest("ghcr.io", matches[1]+"/"+matches[2], matches[3])
+	if err != nil {
+		return "", err
+	}
+
+	return updater.SetDigest(line, matches[0], digest), nil
+}
+
+func packageTags(org, packageName string) []string {
+	client := github.Client()
+
+	var ret []string
+
+	for page := 1; page != 0; {
+		versions, resp, err := client.Organizations.PackageGetAllVersions(context.Background(), org, "container", packageName, &gh.PackageListOptions{
+			ListOptions: gh.ListOptions{
+				Page:    page,
+				PerPage: 100,
+			},
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"org":     org,
+				"package": packageName,
+				"page":    page,
+				"err":     err,
+			}).Debug("cannot list package versions")
+
+			break
+		}
+
+		log.WithFields(log.Fields{
+			"len":           len(versions),
+			"org":           org,
+			"package":       packageName,
+			"resp.LastPage": resp.LastPage,
+		}).Debug("found package releases")
+
+		for _, packageVersion := range versions {
+			ret = append(ret, packageVersion.GetMetadata().GetContainer().Tags...)
+		}
+
+		page = resp.NextPage
+	}
+
+	return ret
+}
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mhristof/bump/updater/github"
+)
+
+// Tag is a git tag and the commit it points to.
+type Tag struct {
+	Name   string
+	Commit string
+}
+
+// Tags returns the tags of the git repository at url. GitHub repositories
+// are listed through the API and everything else with git ls-remote.
+func Tags(url string) ([]Tag, error) {
+	if strings.HasPrefix(url, "https://github.com/") {
+		return githubTags(url)
+	}
+
+	return remoteTags(url)
+}
+
+func githubTags(url string) ([]Tag, error) {
+	fields := strings.Split(strings.TrimSuffix(strings.TrimPrefix(url, "https://github.com/"), ".git"), "/")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("cannot find github repository in %s", url)
+	}
+
+	tags, err := github.Tags(github.Client(), fields[0], fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		ret = append(ret, Tag{
+			Name:   tag.GetName(),
+			Commit: tag.GetCommit().GetSHA(),
+		})
+	}
+
+	return ret, nil
+}
+
+func remoteTags(url string) ([]Tag, error) {
+	var stdout, stderr bytes.Buffer
+
+	command := exec.Command("git", "ls-remote", "--tags", url)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	err := command.Run()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list tags of %s: %s: %w", url, strings.TrimSpace(stderr.String()), err)
+	}
+
+	return parseRemoteTags(stdout.String()), nil
+}
+
+// parseRemoteTags parses the output of git ls-remote --tags. The commit of
+// an annotated tag comes from its peeled ^{} entry.
+func parseRemoteTags(stdout string) []Tag {
+	var ret []Tag
+
+	index := map[string]int{}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "refs/tags/") {
+			continue
+		}
+
+		name := strings.TrimPrefix(fields[1], "refs/tags/")
+		peeled := strings.HasSuffix(name, "^{}")
+		name = strings.TrimSuffix(name, "^{}")
+
+		if i, ok := index[name]; ok {
+			if peeled {
+				ret[i].Commit = fields[0]
+			}
+
+			continue
+		}
+
+		index[name] = len(ret)
+		ret = append(ret, Tag{Name: name, Commit: fields[0]})
+	}
+
+	return ret
+}
@@ -0,0 +1,19 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRemoteTags(t *testing.T) {
+	stdout := "1111111111111111111111111111111111111111\trefs/tags/v1.0.0\n" +
+		"2222222222222222222222222222222222222222\trefs/tags/v1.1.0\n" +
+		"3333333333333333333333333333333333333333\trefs/tags/v1.1.0^{}\n" +
+		"4444444444444444444444444444444444444444\trefs/heads/main\n"
+
+	assert.Equal(t, []Tag{
+		{Name: "v1.0.0", Commit: "1111111111111111111111111111111111111111"},
+		{Name: "v1.1.0", Commit: "3333333333333333333333333333333333333333"},
+	}, parseRemoteTags(stdout))
+}
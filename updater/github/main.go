@@ -0,0 +1,121 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	gh "github.com/google/go-github/v50/github"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+func init() {
+	updater.Register("github", 200, func(updater.Options) updater.Updater {
+		return &GitHub{}
+	})
+}
+
+// Client returns a GitHub API client authenticated with GITHUB_READONLY_TOKEN.
+func Client() *gh.Client {
+	ctx := context.Background()
+
+	token := os.Getenv("GITHUB_READONLY_TOKEN")
+
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	tc := oauth2.NewClient(ctx, ts)
+
+	return gh.NewClient(tc)
+}
+
+// Tags returns every tag of the repository owner/repo, with the commit each
+// tag points to.
+func Tags(client *gh.Client, owner, repo string) ([]*gh.RepositoryTag, error) {
+	var ret []*gh.RepositoryTag
+
+	for page := 1; page != 0; {
+		tags, resp, err := client.Repositories.ListTags(context.Background(), owner, repo, &gh.ListOptions{
+			Page:    page,
+			PerPage: 100,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot list tags of %s/%s: %w", owner, repo, err)
+		}
+
+		ret = append(ret, tags...)
+		page = resp.NextPage
+	}
+
+	log.WithFields(log.Fields{
+		"owner": owner,
+		"repo":  repo,
+		"len":   len(ret),
+	}).Debug("found github tags")
+
+	return ret, nil
+}
+
+// GitHub updates links to GitHub releases.
+type GitHub struct{}
+
+var repoRegex = regexp.MustCompile(`https://github.com/([a-zA-Z0-9-]+)/([a-zA-Z0-9-]+)`)
+
+func (g *GitHub) Match(file, line string) bool {
+	return strings.Contains(line, "https://github.com")
+}
+
+func (g *GitHub) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	matches := repoRegex.FindStringSubmatch(line)
+	if len(matches) != 3 {
+		return nil, nil, fmt.Errorf("cannot find github repository in %s", line)
+	}
+
+	owner := matches[1]
+	repo := matches[2]
+
+	releases, resp, err := Client().Repositories.ListReleases(context.TODO(), owner, repo, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot list releases for %s/%s: %w", owner, repo, err)
+	}
+
+	log.WithFields(log.Fields{
+		"len":   len(releases),
+		"repo":  repo,
+		"owner": owner,
+		"resp":  resp,
+	}).Debug("Found releases")
+
+	semverReleases := make([]*semver.Version, 0, len(releases))
+
+	for _, release := range releases {
+		log.WithField("release", release.GetTagName()).Trace("Release")
+
+		version, err := semver.NewVersion(release.GetTagName())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"release": release.GetTagName(),
+				"error":   err,
+			}).Debug("cannot parse release")
+
+			continue
+		}
+
+		semverReleases = append(semverReleases, version)
+	}
+
+	return updater.ExtractVersion(line), semverReleases, nil
+}
+
+func (g *GitHub) Repository(line string) string {
+	return repoRegex.FindString(line)
+}
+
+func (g *GitHub) Rewrite(line string, current, next *semver.Version) string {
+	return strings.ReplaceAll(line, current.String(), next.String())
+}
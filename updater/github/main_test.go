@@ -0,0 +1,44 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGithubUpdate(t *testing.T) {
+	cases := []struct {
+		name       string
+		line       string
+		newLine    string
+		newVersion *semver.Version
+	}{
+		{
+			name:       "simple github url",
+			line:       "https://github.com/mhristof/bump-semver/releases/download/v0.1.0/semver",
+			newLine:    "https://github.com/mhristof/bump-semver/releases/download/v0.17.1/semver",
+			newVersion: semver.MustParse("v0.17.1"),
+		},
+	}
+
+	// log.SetLevel(log.DebugLevel)
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			g := &GitHub{}
+
+			current, versions, err := g.Versions(test.line)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			newVersion := policy.Select(policy.Major, policy.SameTrack, current, versions)
+			if !assert.Equal(t, test.newVersion, newVersion, test.name) {
+				return
+			}
+
+			assert.Equal(t, test.newLine, g.Rewrite(test.line, current, newVersion), test.name)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultURL = "https://gitlab.com"
+
+func init() {
+	updater.Register("gitlab", 300, func(updater.Options) updater.Updater {
+		return New(os.Getenv("GITLAB_URL"), os.Getenv("GITLAB_TOKEN"))
+	})
+}
+
+// GitLab updates links to GitLab releases and tags, on gitlab.com or on a
+// self-hosted instance.
+type GitLab struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// New returns a GitLab updater for the instance at baseURL, which defaults to
+// gitlab.com. token is only sent to that instance.
+func New(baseURL, token string) *GitLab {
+	if baseURL == "" {
+		baseURL = defaultURL
+	}
+
+	return &GitLab{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  http.DefaultClient,
+	}
+}
+
+var (
+	urlRegex     = regexp.MustCompile("https?://[^\\s\"'`]+")
+	gitlabMarker = regexp.MustCompile(`/-/(releases|tags|archive|raw|package_files)/`)
+)
+
+// link is a GitLab URL split into its instance, project and the path that
+// follows the project.
+type link struct {
+	base    string
+	project string
+	rest    string
+}
+
+// find returns the first GitLab URL in line.
+func (g *GitLab) find(line string) (link, bool) {
+	for _, candidate := range urlRegex.FindAllString(line, -1) {
+		var base, path string
+
+		switch {
+		case strings.HasPrefix(candidate, g.baseURL+"/"):
+			base = g.baseURL
+		case strings.HasPrefix(candidate, defaultURL+"/"):
+			base = defaultURL
+		case gitlabMarker.MatchString(candidate):
+			parsed, err := url.Parse(candidate)
+			if err != nil {
+				continue
+			}
+
+			base = parsed.Scheme + "://" + parsed.Host
+		default:
+			continue
+		}
+
+		path = strings.TrimPrefix(candidate, base+"/")
+
+		var rest string
+
+		if i := strings.Index(path, "/-/"); i >= 0 {
+			path, rest = path[:i], path[i:]
+		} else {
+			fields := strings.SplitN(path, "/", 3)
+			if len(fields) < 2 {
+				continue
+			}
+
+			path = fields[0] + "/" + fields[1]
+			rest = strings.TrimPrefix(candidate, base+"/"+path)
+		}
+
+		return link{
+			base:    base,
+			project: strings.TrimSuffix(path, ".git"),
+			rest:    rest,
+		}, true
+	}
+
+	return link{}, false
+}
+
+func (g *GitLab) Match(file, line string) bool {
+	_, ok := g.find(line)
+
+	return ok
+}
+
+func (g *GitLab) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	found, ok := g.find(line)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot find gitlab project in %s", line)
+	}
+
+	base, project := found.base, found.project
+
+	log.WithFields(log.Fields{
+		"base":    base,
+		"project": project,
+	}).Debug("Updating gitlab link")
+
+	tags, err := g.list(base, project, "releases")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(tags) == 0 {
+		tags, err = g.list(base, project, "repository/tags")
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var versions []*semver.Version
+
+	for _, tag := range tags {
+		version, err := semver.NewVersion(tag)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"project": project,
+				"tag":     tag,
+				"error":   err,
+			}).Trace("cannot parse tag")
+
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	log.WithFields(log.Fields{
+		"project": project,
+		"len":     len(versions),
+	}).Debug("Found gitlab versions")
+
+	current := updater.ExtractVersion(found.rest)
+	if current == nil {
+		return nil, nil, fmt.Errorf("cannot find version of %s in %s", project, line)
+	}
+
+	return current, versions, nil
+}
+
+func (g *GitLab) Rewrite(line string, current, next *semver.Version) string {
+	return strings.ReplaceAll(line, current.String(), next.String())
+}
+
+type ref struct {
+	Name    string `json:"name"`
+	TagName string `json:"tag_name"`
+}
+
+// list returns the tag names of every page of the releases or
+// repository/tags endpoint of project.
+func (g *GitLab) list(base, project, endpoint string) ([]string, error) {
+	var ret []string
+
+	for page := "1"; page != ""; {
+		apiURL := fmt.Sprintf("%s/api/v4/projects/%s/%s?per_page=100&page=%s", base, url.PathEscape(project), endpoint, page)
+
+		req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create request for %s: %w", apiURL, err)
+		}
+
+		if g.token != "" && base == g.baseURL {
+			req.Header.Set("PRIVATE-TOKEN", g.token)
+		}
+
+		resp, err := g.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get %s: %w", apiURL, err)
+		}
+
+		var refs []ref
+
+		err = json.NewDecoder(resp.Body).Decode(&refs)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("cannot get %s: %s", apiURL, resp.Status)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode %s: %w", apiURL, err)
+		}
+
+		for _, r := range refs {
+			if r.TagName != "" {
+				ret = append(ret, r.TagName)
+
+				continue
+			}
+
+			ret = append(ret, r.Name)
+		}
+
+		page = resp.Header.Get("X-Next-Page")
+	}
+
+	return ret, nil
+}
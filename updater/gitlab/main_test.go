@@ -0,0 +1,120 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func server(t *testing.T, token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, token, r.Header.Get("PRIVATE-TOKEN"))
+
+		switch r.URL.EscapedPath() + "?" + r.URL.RawQuery {
+		case "/api/v4/projects/infra%2Ftools%2Fcli/releases?per_page=100&page=1":
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"tag_name": "v1.3.0"}, {"tag_name": "latest"}]`)
+		case "/api/v4/projects/infra%2Ftools%2Fcli/releases?per_page=100&page=2":
+			fmt.Fprint(w, `[{"tag_name": "v1.10.0"}, {"tag_name": "v1.2.3"}]`)
+		case "/api/v4/projects/infra%2Fnoreleases/releases?per_page=100&page=1":
+			fmt.Fprint(w, `[]`)
+		case "/api/v4/projects/infra%2Fnoreleases/repository/tags?per_page=100&page=1":
+			fmt.Fprint(w, `[{"name": "2.0.0"}, {"name": "1.0.0"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestGitLab(t *testing.T) {
+	srv := server(t, "secret")
+	defer srv.Close()
+
+	cases := []struct {
+		name    string
+		line    string
+		newLine string
+	}{
+		{
+			name:    "release download from a subgroup",
+			line:    srv.URL + "/infra/tools/cli/-/releases/v1.2.3/downloads/cli_1.2.3_linux_amd64.tar.gz",
+			newLine: srv.URL + "/infra/tools/cli/-/releases/v1.10.0/downloads/cli_1.10.0_linux_amd64.tar.gz",
+		},
+		{
+			name:    "project without releases falls back to tags",
+			line:    `url = "` + srv.URL + `/infra/noreleases/-/archive/1.0.0/noreleases-1.0.0.tar.gz"`,
+			newLine: `url = "` + srv.URL + `/infra/noreleases/-/archive/2.0.0/noreleases-2.0.0.tar.gz"`,
+		},
+	}
+
+	g := New(srv.URL+"/", "secret")
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.True(t, g.Match("", test.line), test.name)
+
+			current, versions, err := g.Versions(test.line)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			next := policy.Select(policy.Major, policy.SameTrack, current, versions)
+			if !assert.NotNil(t, next, test.name) {
+				return
+			}
+
+			assert.Equal(t, test.newLine, g.Rewrite(test.line, current, next), test.name)
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	cases := []struct {
+		name    string
+		line    string
+		base    string
+		project string
+		match   bool
+	}{
+		{
+			name:    "gitlab.com release",
+			line:    "https://gitlab.com/gitlab-org/cli/-/releases/v1.30.0/downloads/glab_1.30.0_Linux_x86_64.tar.gz",
+			base:    "https://gitlab.com",
+			project: "gitlab-org/cli",
+			match:   true,
+		},
+		{
+			name:    "gitlab.com project link",
+			line:    "https://gitlab.com/gitlab-org/cli.git v1.30.0",
+			base:    "https://gitlab.com",
+			project: "gitlab-org/cli",
+			match:   true,
+		},
+		{
+			name:    "unknown self-hosted instance",
+			line:    "https://git.example.com/a/b/c/-/releases/v1.0.0/downloads/c",
+			base:    "https://git.example.com",
+			project: "a/b/c",
+			match:   true,
+		},
+		{
+			name: "github link",
+			line: "https://github.com/mhristof/bump/releases/download/v0.1.0/bump",
+		},
+	}
+
+	g := New("", "")
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			found, ok := g.find(test.line)
+			assert.Equal(t, test.match, ok, test.name)
+			assert.Equal(t, test.base, found.base, test.name)
+			assert.Equal(t, test.project, found.project, test.name)
+		})
+	}
+}
@@ -1,11 +1,185 @@
-package tool
+package updater
 
-type Change struct {
-	Repo       string
-	Version    string
-	OldVersion string
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Updater is implemented by every source bump can query for newer versions.
+type Updater interface {
+	// Match reports whether line, found in file, is handled by the updater.
+	// file is empty when the line was passed on the command line.
+	Match(file, line string) bool
+	// Versions returns the version pinned in line and the versions
+	// available upstream, in any order.
+	Versions(line string) (*semver.Version, []*semver.Version, error)
+	// Rewrite returns line with current replaced by next.
+	Rewrite(line string, current, next *semver.Version) string
+}
+
+// Repository is implemented by updaters that know the repository a line
+// points at, such as https://github.com/org/repo. It is used to link the
+// release notes of a change.
+type Repository interface {
+	Repository(line string) string
+}
+
+// Pinner is implemented by container registries that can pin an image to
+// the digest of its tag.
+type Pinner interface {
+	// Pin returns line with the image pinned as name:tag@digest, using the
+	// digest the tag points to now. An existing digest is replaced.
+	Pin(line string) (string, error)
+}
+
+// Replacer is implemented by updaters of values that have no version to
+// compare, like date stamped image names. Lines where Versions finds no
+// current version are handed to Replace instead.
+type Replacer interface {
+	// Replace returns line with the value replaced by the newest one, or
+	// line itself when it is the newest.
+	Replace(line string) (string, error)
+}
+
+// Scanner is implemented by updaters that own whole files, like workflows or
+// package manifests, where the versions are not always written as x.y.z.
+// Every line of the files they scan is offered to Match, not only the lines
+// with a version.
+type Scanner interface {
+	Scan(file string) bool
+}
+
+// Options are handed to every Factory when a run starts.
+type Options struct {
+	Threads int
+}
+
+// Factory creates the Updater used for a single run.
+type Factory func(Options) Updater
+
+type registration struct {
+	name     string
+	priority int
+	factory  Factory
 }
 
-type Update interface {
-	Update(string, bool) ([]Change, error)
+var (
+	registry   = map[string]registration{}
+	registryMu sync.Mutex
+)
+
+// Register makes an updater available under name. When more than one
+// updater matches a line, the one with the highest priority wins and equal
+// priorities are resolved by name. Register panics if name is already taken.
+func Register(name string, priority int, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("updater: Register factory is nil for " + name)
+	}
+
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("updater: Register called twice for %s", name))
+	}
+
+	registry[name] = registration{
+		name:     name,
+		priority: priority,
+		factory:  factory,
+	}
+}
+
+// Named is an Updater together with the name it was registered under.
+type Named struct {
+	Name string
+	Updater
+}
+
+// Set holds the updaters of a run in matching order.
+type Set []Named
+
+// New instantiates every registered updater.
+func New(opts Options) Set {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registrations := make([]registration, 0, len(registry))
+	for _, r := range registry {
+		registrations = append(registrations, r)
+	}
+
+	sort.Slice(registrations, func(i, j int) bool {
+		if registrations[i].priority != registrations[j].priority {
+			return registrations[i].priority > registrations[j].priority
+		}
+
+		return registrations[i].name < registrations[j].name
+	})
+
+	ret := make(Set, 0, len(registrations))
+	for _, r := range registrations {
+		ret = append(ret, Named{
+			Name:    r.name,
+			Updater: r.factory(opts),
+		})
+	}
+
+	return ret
+}
+
+// Names returns the names of the updaters in s, in matching order.
+func (s Set) Names() []string {
+	ret := make([]string, len(s))
+	for i, u := range s {
+		ret[i] = u.Name
+	}
+
+	return ret
+}
+
+// Match returns the first updater of s that handles line, or nil.
+func (s Set) Match(file, line string) *Named {
+	for i := range s {
+		if s[i].Match(file, line) {
+			return &s[i]
+		}
+	}
+
+	return nil
+}
+
+// Scans reports whether an updater of s scans every line of file.
+func (s Set) Scans(file string) bool {
+	for _, u := range s {
+		if scanner, ok := u.Updater.(Scanner); ok && scanner.Scan(file) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// semverRegex only takes pre-releases that start with a common pre-release
+// word, so the -linux of tool-1.2.3-linux.tar.gz is not part of the version.
+var semverRegex = regexp.MustCompile(`v?\d+\.\d+\.\d+(?:-(?i:alpha|beta|rc|pre|dev)(?:[.-]?[0-9A-Za-z]+)*)?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?`)
+
+// ExtractVersion returns the first semantic version found in line, with its
+// pre-release and build metadata.
+func ExtractVersion(line string) *semver.Version {
+	match := semverRegex.FindString(line)
+	if match == "" {
+		return nil
+	}
+
+	ret, err := semver.NewVersion(match)
+	if err != nil {
+		return nil
+	}
+
+	return ret
 }
@@ -0,0 +1,181 @@
+package updater_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/updater"
+	_ "github.com/mhristof/bump/updater/actions"
+	_ "github.com/mhristof/bump/updater/ami"
+	_ "github.com/mhristof/bump/updater/dockerhub"
+	_ "github.com/mhristof/bump/updater/ecr"
+	_ "github.com/mhristof/bump/updater/ghcr"
+	_ "github.com/mhristof/bump/updater/github"
+	_ "github.com/mhristof/bump/updater/gitlab"
+	_ "github.com/mhristof/bump/updater/npm"
+	_ "github.com/mhristof/bump/updater/oci"
+	_ "github.com/mhristof/bump/updater/pypi"
+	_ "github.com/mhristof/bump/updater/rubygems"
+	"github.com/stretchr/testify/assert"
+)
+
+type fake struct {
+	substring string
+}
+
+func (f fake) Match(file, line string) bool {
+	return strings.Contains(line, f.substring)
+}
+
+func (f fake) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	return nil, nil, nil
+}
+
+func (f fake) Rewrite(line string, current, next *semver.Version) string {
+	return line
+}
+
+func init() {
+	updater.Register("test-zzz", 150, func(updater.Options) updater.Updater { return fake{"internal.example"} })
+	updater.Register("test-aaa", 150, func(updater.Options) updater.Updater { return fake{"internal.example"} })
+	updater.Register("test-high", 1000, func(updater.Options) updater.Updater { return fake{"registry.internal"} })
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		name string
+		file string
+		line string
+		want string
+	}{
+		{
+			name: "ecr image with a github link",
+			line: `image = "123456789012.dkr.ecr.eu-west-1.amazonaws.com/foo:1.2.3" # https://github.com/org/foo`,
+			want: "ecr",
+		},
+		{
+			name: "ghcr image with a github link",
+			line: "ghcr.io/org/foo:v1.2.3 https://github.com/org/foo",
+			want: "ghcr",
+		},
+		{
+			name: "github release",
+			line: "https://github.com/mhristof/bump-semver/releases/download/v0.1.0/semver",
+			want: "github",
+		},
+		{
+			name: "gitlab release",
+			line: "https://gitlab.com/gitlab-org/cli/-/releases/v1.30.0/downloads/glab_1.30.0_Linux_x86_64.tar.gz",
+			want: "gitlab",
+		},
+		{
+			name: "dockerhub image",
+			line: "prom/alertmanager:v0.25.0",
+			want: "dockerhub",
+		},
+		{
+			name: "registry image",
+			line: "quay.io/prometheus/prometheus:v2.45.0",
+			want: "oci",
+		},
+		{
+			name: "same priority is resolved by name",
+			line: "internal.example/foo:1.2.3",
+			want: "test-aaa",
+		},
+		{
+			name: "higher priority wins over builtin updaters",
+			line: "registry.internal/foo:1.2.3 https://github.com/org/foo",
+			want: "test-high",
+		},
+		{
+			name: "workflow action",
+			file: ".github/workflows/ci.yml",
+			line: "      - uses: actions/checkout@v4",
+			want: "actions",
+		},
+		{
+			name: "requirements pin",
+			file: "requirements-dev.txt",
+			line: "requests==2.31.0",
+			want: "pypi",
+		},
+		{
+			name: "gemfile pin",
+			file: "Gemfile",
+			line: `gem "rails", "~> 7.0"`,
+			want: "rubygems",
+		},
+		{
+			name: "action outside of a workflow",
+			file: "README.md",
+			line: "      - uses: actions/checkout@v4",
+		},
+		{
+			name: "no updater",
+			line: "version = 1.2.3",
+		},
+	}
+
+	updaters := updater.New(updater.Options{Threads: 1})
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			u := updaters.Match(test.file, test.line)
+			if test.want == "" {
+				assert.Nil(t, u, test.name)
+
+				return
+			}
+
+			if assert.NotNil(t, u, test.name) {
+				assert.Equal(t, test.want, u.Name, test.name)
+			}
+		})
+	}
+}
+
+func TestNewOrder(t *testing.T) {
+	names := updater.New(updater.Options{}).Names()
+
+	assert.Equal(t, []string{"test-high", "ecr", "ghcr", "ami", "gitlab", "actions", "npm", "pypi", "rubygems", "github", "test-aaa", "test-zzz", "oci", "dockerhub"}, names)
+}
+
+func TestRegisterTwice(t *testing.T) {
+	assert.Panics(t, func() {
+		updater.Register("ecr", 0, func(updater.Options) updater.Updater { return fake{} })
+	})
+}
+
+func TestExtractVersion(t *testing.T) {
+	cases := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "plain", line: "image: org/app:1.2.3", want: "1.2.3"},
+		{name: "v prefix", line: "https://github.com/org/repo/releases/tag/v1.2.3", want: "v1.2.3"},
+		{name: "pre-release", line: "app:2.0.0-rc.1 # next", want: "2.0.0-rc.1"},
+		{name: "build metadata", line: "version = \"1.2.3+build.5\"", want: "1.2.3+build.5"},
+		{name: "pre-release and build metadata", line: "1.2.3-beta.2+exp.sha.5114f85", want: "1.2.3-beta.2+exp.sha.5114f85"},
+		{name: "platform suffix", line: "tool-1.2.3-linux-amd64.tar.gz", want: "1.2.3"},
+		{name: "trailing dot", line: "Use 2.0.0-rc.1.", want: "2.0.0-rc.1"},
+		{name: "no version", line: "nothing to see"},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got := updater.ExtractVersion(test.line)
+			if test.want == "" {
+				assert.Nil(t, got, test.name)
+
+				return
+			}
+
+			if assert.NotNil(t, got, test.name) {
+				assert.Equal(t, test.want, got.Original(), test.name)
+			}
+		})
+	}
+}
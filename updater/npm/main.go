@@ -0,0 +1,207 @@
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultURL = "https://registry.npmjs.org"
+
+func init() {
+	updater.Register("npm", 250, func(updater.Options) updater.Updater {
+		return New(defaultURL)
+	})
+}
+
+// NPM updates the dependencies of package.json files. Exact versions and
+// the ^, ~, = and >= ranges of a single version are updated, keeping the
+// operator.
+type NPM struct {
+	baseURL string
+	client  *http.Client
+
+	mu   sync.Mutex
+	deps map[string]map[string]string
+}
+
+// New returns an updater for the npm registry at baseURL.
+func New(baseURL string) *NPM {
+	return &NPM{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  http.DefaultClient,
+		deps:    map[string]map[string]string{},
+	}
+}
+
+// depRegex matches a "name": "range" member written in a single line.
+var depRegex = regexp.MustCompile(`^\s*"((?:@[\w.-]+/)?[\w.-]+)"\s*:\s*"(\^|~|=|>=)?(v?\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)"\s*,?\s*$`)
+
+// Scan reports whether file is a package.json.
+func (n *NPM) Scan(file string) bool {
+	return filepath.Base(file) == "package.json"
+}
+
+// Match reports whether line is a member of the dependencies of the
+// package.json file. Members like "version" have the same shape, so the
+// file is read to tell them apart.
+func (n *NPM) Match(file, line string) bool {
+	if !n.Scan(file) {
+		return false
+	}
+
+	matches := depRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return false
+	}
+
+	spec, ok := n.dependencies(file)[matches[1]]
+
+	return ok && spec == matches[2]+matches[3]
+}
+
+// dependencies returns every dependency of the package.json at path, of any
+// kind, with its range.
+func (n *NPM) dependencies(path string) map[string]string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if deps, ok := n.deps[path]; ok {
+		return deps
+	}
+
+	deps := map[string]string{}
+	n.deps[path] = deps
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  path,
+			"error": err,
+		}).Debug("cannot read package.json")
+
+		return deps
+	}
+
+	var manifest map[string]json.RawMessage
+
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  path,
+			"error": err,
+		}).Debug("cannot parse package.json")
+
+		return deps
+	}
+
+	for _, key := range []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"} {
+		var section map[string]string
+
+		if json.Unmarshal(manifest[key], &section) != nil {
+			continue
+		}
+
+		for name, spec := range section {
+			deps[name] = spec
+		}
+	}
+
+	return deps
+}
+
+func (n *NPM) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	matches := depRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, nil, fmt.Errorf("cannot find dependency in %s", line)
+	}
+
+	current, err := semver.NewVersion(matches[3])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	versions, err := n.packageVersions(matches[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return current, versions, nil
+}
+
+// packageVersions returns the versions of the package name that are not
+// deprecated.
+func (n *NPM) packageVersions(name string) ([]*semver.Version, error) {
+	// Scoped packages are requested as @scope%2fname.
+	apiURL := n.baseURL + "/" + strings.Replace(name, "/", "%2f", 1)
+
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request for %s: %w", apiURL, err)
+	}
+
+	// The abbreviated metadata is much smaller and has everything needed.
+	req.Header.Set("Accept", "application/vnd.npm.install-v1+json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get %s: %w", apiURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get %s: %s", apiURL, resp.Status)
+	}
+
+	var metadata struct {
+		Versions map[string]struct {
+			Deprecated string `json:"deprecated"`
+		} `json:"versions"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&metadata)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %w", apiURL, err)
+	}
+
+	var ret []*semver.Version
+
+	for v, info := range metadata.Versions {
+		if info.Deprecated != "" {
+			continue
+		}
+
+		version, err := semver.NewVersion(v)
+		if err != nil {
+			continue
+		}
+
+		ret = append(ret, version)
+	}
+
+	log.WithFields(log.Fields{
+		"package": name,
+		"len":     len(ret),
+	}).Debug("found npm versions")
+
+	return ret, nil
+}
+
+// Rewrite sets the version of the range to next, keeping its operator.
+func (n *NPM) Rewrite(line string, current, next *semver.Version) string {
+	matches := depRegex.FindStringSubmatchIndex(line)
+	if matches == nil {
+		return line
+	}
+
+	return line[:matches[6]] + next.Original() + line[matches[7]:]
+}
@@ -0,0 +1,111 @@
+package npm
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+const manifest = `{
+  "name": "app",
+  "version": "1.0.0",
+  "engines": {
+    "node": ">=18.0.0"
+  },
+  "dependencies": {
+    "react": "^18.2.0",
+    "@types/node": "~20.4.1"
+  },
+  "devDependencies": {
+    "typescript": "5.1.6",
+    "eslint": "^8.0.0 || ^9.0.0"
+  }
+}
+`
+
+func server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/react":
+			fmt.Fprint(w, `{"versions": {"18.2.0": {}, "18.3.1": {}, "19.0.0-rc.0": {}}}`)
+		case "/@types%2fnode":
+			fmt.Fprint(w, `{"versions": {"20.4.1": {}, "20.5.0": {}, "20.5.1": {"deprecated": "broken"}}}`)
+		case "/typescript":
+			fmt.Fprint(w, `{"versions": {"5.1.6": {}, "5.2.2": {}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestNPM(t *testing.T) {
+	srv := server()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "package.json")
+
+	err := os.WriteFile(path, []byte(manifest), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		line    string
+		newLine string
+	}{
+		{
+			name:    "caret range",
+			line:    `    "react": "^18.2.0",`,
+			newLine: `    "react": "^18.3.1",`,
+		},
+		{
+			name:    "scoped package without deprecated versions",
+			line:    `    "@types/node": "~20.4.1"`,
+			newLine: `    "@types/node": "~20.5.0"`,
+		},
+		{
+			name:    "exact version",
+			line:    `    "typescript": "5.1.6",`,
+			newLine: `    "typescript": "5.2.2",`,
+		},
+	}
+
+	n := New(srv.URL)
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			if !assert.True(t, n.Match(path, test.line), test.name) {
+				return
+			}
+
+			current, versions, err := n.Versions(test.line)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			next := policy.Select(policy.Major, policy.SameTrack, current, versions)
+			if !assert.NotNil(t, next, test.name) {
+				return
+			}
+
+			assert.Equal(t, test.newLine, n.Rewrite(test.line, current, next), test.name)
+		})
+	}
+
+	for _, line := range []string{
+		`  "version": "1.0.0",`,
+		`    "node": ">=18.0.0"`,
+		`    "eslint": "^8.0.0 || ^9.0.0"`,
+	} {
+		assert.False(t, n.Match(path, line), line)
+	}
+
+	assert.False(t, n.Match(filepath.Join(filepath.Dir(path), "tsconfig.json"), `    "react": "^18.2.0",`))
+}
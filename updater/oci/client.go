@@ -0,0 +1,355 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Client talks to registries that implement the OCI Distribution API. It
+// answers the Bearer and Basic challenges of the registries with the
+// credentials of the docker config, or anonymously.
+type Client struct {
+	client *http.Client
+	auths  map[string]string
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewClient returns a client that reads the credentials of every registry
+// from the auths of the docker config at path. A missing config means
+// anonymous access.
+func NewClient(path string) *Client {
+	ret := &Client{
+		client: http.DefaultClient,
+		auths:  map[string]string{},
+		tokens: map[string]string{},
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.WithFields(log.Fields{
+				"path":  path,
+				"error": err,
+			}).Warning("cannot read docker config")
+		}
+
+		return ret
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path":  path,
+			"error": err,
+		}).Warning("cannot parse docker config")
+
+		return ret
+	}
+
+	for host, auth := range config.Auths {
+		if auth.Auth == "" {
+			continue
+		}
+
+		host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+		ret.auths[strings.TrimSuffix(strings.Split(host, "/")[0], "/")] = auth.Auth
+	}
+
+	return ret
+}
+
+// DockerConfig returns the path of the docker config, honoring
+// DOCKER_CONFIG.
+func DockerConfig() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// auth returns the credentials of host. Docker Hub logins are stored under
+// index.docker.io but the registry is served from registry-1.docker.io.
+func (c *Client) auth(host string) (string, bool) {
+	if host == "registry-1.docker.io" || host == "docker.io" {
+		host = "index.docker.io"
+	}
+
+	auth, ok := c.auths[host]
+
+	return auth, ok
+}
+
+// baseURL returns the URL of the registry at host. Registries on the
+// loopback interface are reached over plain HTTP, like docker does.
+func baseURL(host string) string {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+
+	if hostname == "localhost" {
+		return "http://" + host
+	}
+
+	if ip := net.ParseIP(hostname); ip != nil && ip.IsLoopback() {
+		return "http://" + host
+	}
+
+	return "https://" + host
+}
+
+var linkRegex = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// Tags returns every tag of the repository name on the registry at host,
+// following the Link header of paginated responses.
+func (c *Client) Tags(host, name string) ([]string, error) {
+	var ret []string
+
+	base := baseURL(host)
+
+	for next := fmt.Sprintf("%s/v2/%s/tags/list?n=1000", base, name); next != ""; {
+		resp, err := c.do(http.MethodGet, host, name, next, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("cannot list tags of %s/%s: %s", host, name, resp.Status)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode tags of %s/%s: %w", host, name, err)
+		}
+
+		ret = append(ret, list.Tags...)
+
+		next = ""
+
+		if matches := linkRegex.FindStringSubmatch(resp.Header.Get("Link")); matches != nil {
+			link, err := url.Parse(matches[1])
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse link %s: %w", matches[1], err)
+			}
+
+			next = resp.Request.URL.ResolveReference(link).String()
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"host": host,
+		"name": name,
+		"len":  len(ret),
+	}).Debug("found registry tags")
+
+	return ret, nil
+}
+
+// manifestTypes are accepted when resolving a digest. The index and manifest
+// list types come first so multi-platform images resolve to the digest of
+// the whole list.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Digest returns the digest of the manifest, or manifest list, that
+// reference points to in the repository name on the registry at host.
+func (c *Client) Digest(host, name, reference string) (string, error) {
+	apiURL := fmt.Sprintf("%s/v2/%s/manifests/%s", baseURL(host), name, reference)
+	header := http.Header{"Accept": []string{strings.Join(manifestTypes, ", ")}}
+
+	resp, err := c.do(http.MethodHead, host, name, apiURL, header)
+	if err != nil {
+		return "", err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK && resp.Header.Get("Docker-Content-Digest") != "" {
+		return resp.Header.Get("Docker-Content-Digest"), nil
+	}
+
+	resp, err = c.do(http.MethodGet, host, name, apiURL, header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot get manifest %s of %s/%s: %s", reference, host, name, resp.Status)
+	}
+
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	hash := sha256.New()
+
+	_, err = io.Copy(hash, resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("cannot read manifest %s of %s/%s: %w", reference, host, name, err)
+	}
+
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
+
+// do sends a method request to apiURL and answers the authentication
+// challenge of the registry once when it is needed.
+func (c *Client) do(method, host, name, apiURL string, header http.Header) (*http.Response, error) {
+	key := host + "/" + name
+
+	do := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequest(method, apiURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create request for %s: %w", apiURL, err)
+		}
+
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get %s: %w", apiURL, err)
+		}
+
+		return resp, nil
+	}
+
+	c.mu.Lock()
+	authorization := c.tokens[key]
+	c.mu.Unlock()
+
+	resp, err := do(authorization)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+
+	authorization, err = c.authorize(host, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.tokens[key] = authorization
+	c.mu.Unlock()
+
+	return do(authorization)
+}
+
+var paramRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authorize returns the Authorization header that answers challenge.
+func (c *Client) authorize(host, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		auth, ok := c.auth(host)
+		if !ok {
+			return "", fmt.Errorf("registry %s requires credentials", host)
+		}
+
+		return "Basic " + auth, nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("unsupported challenge %q from %s", challenge, host)
+	}
+
+	values := map[string]string{}
+	for _, match := range paramRegex.FindAllStringSubmatch(params, -1) {
+		values[strings.ToLower(match[1])] = match[2]
+	}
+
+	realm, err := url.Parse(values["realm"])
+	if err != nil || values["realm"] == "" {
+		return "", fmt.Errorf("invalid realm in challenge %q from %s", challenge, host)
+	}
+
+	query := realm.Query()
+	for _, param := range []string{"service", "scope"} {
+		if values[param] != "" {
+			query.Set(param, values[param])
+		}
+	}
+
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot create token request for %s: %w", host, err)
+	}
+
+	if auth, ok := c.auth(host); ok {
+		req.Header.Set("Authorization", "Basic "+auth)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("cannot get token for %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot get token for %s: %s", host, resp.Status)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("cannot decode token for %s: %w", host, err)
+	}
+
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	return "Bearer " + token.Token, nil
+}
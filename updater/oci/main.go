@@ -0,0 +1,131 @@
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	updater.Register("oci", 120, func(updater.Options) updater.Updater {
+		return &OCI{config: DockerConfig()}
+	})
+}
+
+// OCI updates container images on any registry that implements the OCI
+// Distribution API, such as quay.io, gcr.io, registry.k8s.io or Harbor.
+type OCI struct {
+	config string
+	once   sync.Once
+	client *Client
+}
+
+// New returns an OCI updater that uses client.
+func New(client *Client) *OCI {
+	return &OCI{client: client}
+}
+
+func (o *OCI) registry() *Client {
+	o.once.Do(func() {
+		if o.client == nil {
+			o.client = NewClient(o.config)
+		}
+	})
+
+	return o.client
+}
+
+// imageRegex matches host/name:tag images whose host has a dot or a port,
+// or is localhost.
+var imageRegex = regexp.MustCompile(`(?:^|[^\w./-])(((?:localhost|[\w-]+(?:\.[\w-]+)+)(?::\d+)?|[\w-]+:\d+)/([\w.-]+(?:/[\w.-]+)*):([\w][\w.-]*))`)
+
+// dockerHub is served by the dockerhub updater.
+var dockerHub = map[string]struct{}{
+	"docker.io":            {},
+	"index.docker.io":      {},
+	"registry-1.docker.io": {},
+}
+
+type reference struct {
+	image string
+	host  string
+	name  string
+	tag   string
+}
+
+func find(line string) (reference, bool) {
+	for _, matches := range imageRegex.FindAllStringSubmatch(line, -1) {
+		if _, ok := dockerHub[matches[2]]; ok {
+			continue
+		}
+
+		return reference{
+			image: matches[1],
+			host:  matches[2],
+			name:  matches[3],
+			tag:   matches[4],
+		}, true
+	}
+
+	return reference{}, false
+}
+
+func (o *OCI) Match(file, line string) bool {
+	_, ok := find(line)
+
+	return ok
+}
+
+func (o *OCI) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	ref, ok := find(line)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot find image in %s", line)
+	}
+
+	current, variant, err := updater.SplitTag(ref.tag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse tag %s of %s/%s: %w", ref.tag, ref.host, ref.name, err)
+	}
+
+	log.WithFields(log.Fields{
+		"host":    ref.host,
+		"name":    ref.name,
+		"tag":     ref.tag,
+		"variant": variant,
+	}).Debug("Updating OCI image")
+
+	tags, err := o.registry().Tags(ref.host, ref.name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return current, updater.TagVersions(tags, variant), nil
+}
+
+func (o *OCI) Rewrite(line string, current, next *semver.Version) string {
+	ref, ok := find(line)
+	if !ok {
+		return line
+	}
+
+	return updater.ReplaceTag(line, ref.image, next)
+}
+
+// Pin pins the image in line to the digest of its tag.
+func (o *OCI) Pin(line string) (string, error) {
+	ref, ok := find(line)
+	if !ok {
+		return "", fmt.Errorf("cannot find image in %s", line)
+	}
+
+	digest, err := o.registry().Digest(ref.host, ref.name, ref.tag)
+	if err != nil {
+		return "", err
+	}
+
+	return updater.SetDigest(line, ref.image, digest), nil
+}
@@ -0,0 +1,215 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+// registry serves team/app with a token challenge and two pages of tags, and
+// public/app without authentication. Manifests of team/app answer HEAD with
+// their digest while public/app only serves the manifest itself.
+func registry(t *testing.T, credentials string) *httptest.Server {
+	var srv *httptest.Server
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			assert.Equal(t, "registry.test", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:team/app:pull", r.URL.Query().Get("scope"))
+
+			if r.Header.Get("Authorization") != "Basic "+credentials {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			fmt.Fprint(w, `{"access_token": "secret-token"}`)
+		case r.URL.Path == "/v2/public/app/manifests/1.1.0":
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, manifest)
+			}
+		case r.URL.Path == "/v2/public/app/tags/list":
+			fmt.Fprint(w, `{"name": "public/app", "tags": ["1.0.0", "1.1.0", "1.1.0-alpine"]}`)
+		case strings.HasPrefix(r.URL.Path, "/v2/team/app/"):
+			if r.Header.Get("Authorization") != "Bearer secret-token" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.test",scope="repository:team/app:pull"`, srv.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			if strings.HasPrefix(r.URL.Path, "/v2/team/app/manifests/") {
+				assert.Contains(t, r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json")
+				w.Header().Set("Docker-Content-Digest", digests[strings.TrimPrefix(r.URL.Path, "/v2/team/app/manifests/")])
+
+				return
+			}
+
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/team/app/tags/list?n=1000&last=v1.2.0>; rel="next"`)
+				fmt.Fprint(w, `{"name": "team/app", "tags": ["latest", "v1.2.0", "v1.2.0-alpine"]}`)
+
+				return
+			}
+
+			fmt.Fprint(w, `{"name": "team/app", "tags": ["v1.10.0", "v1.3.0-alpine", "v2.0.0-rc.1"]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return srv
+}
+
+const manifest = `{"schemaVersion": 2}`
+
+var digests = map[string]string{
+	"v1.10.0":       "sha256:" + strings.Repeat("a", 64),
+	"v1.3.0-alpine": "sha256:" + strings.Repeat("b", 64),
+}
+
+func dockerConfig(t *testing.T, host, credentials string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	err := os.WriteFile(path, []byte(fmt.Sprintf(`{"auths": {"https://%s": {"auth": "%s"}}}`, host, credentials)), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestOCI(t *testing.T) {
+	credentials := base64.StdEncoding.EncodeToString([]byte("user:password"))
+
+	srv := registry(t, credentials)
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	cases := []struct {
+		name    string
+		line    string
+		newLine string
+	}{
+		{
+			name:    "token challenge and pagination",
+			line:    fmt.Sprintf(`image = "%s/team/app:v1.2.0"`, host),
+			newLine: fmt.Sprintf(`image = "%s/team/app:v1.10.0"`, host),
+		},
+		{
+			name:    "variant",
+			line:    fmt.Sprintf("FROM %s/team/app:v1.2.0-alpine AS build", host),
+			newLine: fmt.Sprintf("FROM %s/team/app:v1.3.0-alpine AS build", host),
+		},
+		{
+			name:    "anonymous",
+			line:    fmt.Sprintf("    image: %s/public/app:1.0.0", host),
+			newLine: fmt.Sprintf("    image: %s/public/app:1.1.0", host),
+		},
+	}
+
+	o := New(NewClient(dockerConfig(t, host, credentials)))
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			if !assert.True(t, o.Match("", test.line), test.name) {
+				return
+			}
+
+			current, versions, err := o.Versions(test.line)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			next := policy.Select(policy.Major, policy.SameTrack, current, versions)
+			if !assert.NotNil(t, next, test.name) {
+				return
+			}
+
+			assert.Equal(t, test.newLine, o.Rewrite(test.line, current, next), test.name)
+		})
+	}
+}
+
+func TestOCIWithoutCredentials(t *testing.T) {
+	srv := registry(t, "")
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	_, _, err := New(NewClient("")).Versions(host + "/team/app:v1.2.0")
+	assert.NotNil(t, err)
+}
+
+func TestPin(t *testing.T) {
+	credentials := base64.StdEncoding.EncodeToString([]byte("user:password"))
+
+	srv := registry(t, credentials)
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	old := "sha256:" + strings.Repeat("0", 64)
+
+	cases := []struct {
+		name    string
+		line    string
+		newLine string
+	}{
+		{
+			name:    "digest header",
+			line:    fmt.Sprintf(`image = "%s/team/app:v1.10.0"`, host),
+			newLine: fmt.Sprintf(`image = "%s/team/app:v1.10.0@%s"`, host, digests["v1.10.0"]),
+		},
+		{
+			name:    "replaces the digest",
+			line:    fmt.Sprintf("FROM %s/team/app:v1.3.0-alpine@%s AS build", host, old),
+			newLine: fmt.Sprintf("FROM %s/team/app:v1.3.0-alpine@%s AS build", host, digests["v1.3.0-alpine"]),
+		},
+		{
+			name:    "digest of the manifest",
+			line:    fmt.Sprintf("    image: %s/public/app:1.1.0", host),
+			newLine: fmt.Sprintf("    image: %s/public/app:1.1.0@sha256:%x", host, sha256.Sum256([]byte(manifest))),
+		},
+	}
+
+	o := New(NewClient(dockerConfig(t, host, credentials)))
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := o.Pin(test.line)
+			if assert.Nil(t, err, test.name) {
+				assert.Equal(t, test.newLine, got, test.name)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	o := New(NewClient(""))
+
+	for line, want := range map[string]bool{
+		"quay.io/prometheus/prometheus:v2.45.0":              true,
+		"registry.k8s.io/kube-apiserver:v1.28.2":             true,
+		"gcr.io/distroless/static-debian12:nonroot":          true,
+		"harbor.example.com/team/sub/app:1.2.3":              true,
+		"registry:5000/app:1.2.3":                            true,
+		"localhost:5000/app:1.2.3":                           true,
+		"docker.io/library/alpine:3.18":                      false,
+		"prom/alertmanager:v0.25.0":                          false,
+		"https://example.com/path/to/file:1.2.3":             false,
+		"source = \"git::https://example.com/x.git?ref=v1\"": false,
+	} {
+		assert.Equal(t, want, o.Match("", line), line)
+	}
+}
@@ -0,0 +1,204 @@
+package pypi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultURL = "https://pypi.org"
+
+func init() {
+	updater.Register("pypi", 250, func(updater.Options) updater.Updater {
+		return New(defaultURL)
+	})
+}
+
+// PyPI updates the == pins of pip requirements files.
+type PyPI struct {
+	baseURL string
+	client  *http.Client
+
+	mu sync.Mutex
+	// releases maps the versions of every package looked up to the PEP 440
+	// releases they stand for.
+	releases map[string]map[string]string
+}
+
+// New returns an updater for the PyPI JSON API at baseURL.
+func New(baseURL string) *PyPI {
+	return &PyPI{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		client:   http.DefaultClient,
+		releases: map[string]map[string]string{},
+	}
+}
+
+var (
+	requirementsRegex = regexp.MustCompile(`^(requirements|constraints).*\.(txt|in)$`)
+	// pinRegex matches name[extras]==version, with the environment markers
+	// and comments that may follow.
+	pinRegex = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*==\s*([0-9][0-9A-Za-z.!+-]*)\s*(?:;.*|#.*)?$`)
+	// releaseRegex matches the PEP 440 versions that have a semantic version:
+	// up to three release parts, an optional pre-release and dev release.
+	releaseRegex = regexp.MustCompile(`^(\d+(?:\.\d+){0,2})(?:(a|b|rc)(\d+))?(?:\.dev(\d+))?$`)
+	nameRegex    = regexp.MustCompile(`[-_.]+`)
+	// phases are the PEP 440 pre-release phases in release order.
+	phases = map[string]string{"dev": "0", "a": "1", "b": "2", "rc": "3"}
+)
+
+// Scan reports whether file is a pip requirements or constraints file.
+func (p *PyPI) Scan(file string) bool {
+	return requirementsRegex.MatchString(filepath.Base(file))
+}
+
+func (p *PyPI) Match(file, line string) bool {
+	return p.Scan(file) && pinRegex.MatchString(line)
+}
+
+// Version returns the semantic version of a PEP 440 version. PEP 440 orders
+// the phases dev < a < b < rc < release, which semver pre-release names do
+// not, so the pre-release is written in numbers that sort the same way: the
+// phase, 0 for dev, 1 for a, 2 for b and 3 for rc, and its number. A, b and
+// rc releases end with 1, or with 0 and the dev number for their dev
+// releases. 1.0rc1 is 1.0.0-3.1.1, 1.0rc1.dev2 is 1.0.0-3.1.0.2 and 2.0.dev3
+// is 2.0.0-0.3. Post releases, local versions and epochs have no semantic
+// version.
+func Version(version string) (*semver.Version, error) {
+	matches := releaseRegex.FindStringSubmatch(strings.ToLower(version))
+	if matches == nil {
+		return nil, fmt.Errorf("cannot convert %s to a semantic version", version)
+	}
+
+	ret := matches[1]
+
+	var pre []string
+
+	switch {
+	case matches[2] != "" && matches[4] != "":
+		pre = []string{phases[matches[2]], matches[3], "0", matches[4]}
+	case matches[2] != "":
+		pre = []string{phases[matches[2]], matches[3], "1"}
+	case matches[4] != "":
+		pre = []string{phases["dev"], matches[4]}
+	}
+
+	if len(pre) > 0 {
+		ret += "-" + strings.Join(pre, ".")
+	}
+
+	return semver.NewVersion(ret)
+}
+
+func (p *PyPI) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	matches := pinRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, nil, fmt.Errorf("cannot find pin in %s", line)
+	}
+
+	name := strings.ToLower(nameRegex.ReplaceAllString(matches[1], "-"))
+
+	current, err := Version(matches[2])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	releases, err := p.project(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	originals := map[string]string{current.String(): matches[2]}
+
+	var ret []*semver.Version
+
+	for _, release := range releases {
+		version, err := Version(release)
+		if err != nil {
+			continue
+		}
+
+		originals[version.String()] = release
+		ret = append(ret, version)
+	}
+
+	p.mu.Lock()
+	p.releases[name] = originals
+	p.mu.Unlock()
+
+	return current, ret, nil
+}
+
+// project returns the releases of the package name that have files which
+// are not yanked.
+func (p *PyPI) project(name string) ([]string, error) {
+	url := fmt.Sprintf("%s/pypi/%s/json", p.baseURL, name)
+
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get %s: %s", url, resp.Status)
+	}
+
+	var project struct {
+		Releases map[string][]struct {
+			Yanked bool `json:"yanked"`
+		} `json:"releases"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&project)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %w", url, err)
+	}
+
+	var ret []string
+
+	for release, files := range project.Releases {
+		for _, file := range files {
+			if !file.Yanked {
+				ret = append(ret, release)
+
+				break
+			}
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"package": name,
+		"len":     len(ret),
+	}).Debug("found pypi releases")
+
+	return ret, nil
+}
+
+// Rewrite sets the version of the pin to the release next stands for.
+func (p *PyPI) Rewrite(line string, current, next *semver.Version) string {
+	matches := pinRegex.FindStringSubmatchIndex(line)
+	if matches == nil {
+		return line
+	}
+
+	name := strings.ToLower(nameRegex.ReplaceAllString(line[matches[2]:matches[3]], "-"))
+
+	p.mu.Lock()
+	release, ok := p.releases[name][next.String()]
+	p.mu.Unlock()
+
+	if !ok {
+		return line
+	}
+
+	return line[:matches[4]] + release + line[matches[5]:]
+}
@@ -0,0 +1,159 @@
+package pypi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pypi/requests/json":
+			fmt.Fprint(w, `{"releases": {
+				"2.30.0": [{"yanked": false}],
+				"2.31.0": [{"yanked": false}],
+				"2.32.0": [{"yanked": true}],
+				"2.32.1": [{"yanked": false}, {"yanked": false}],
+				"3.0.0rc1": [{"yanked": false}],
+				"2.0": []
+			}}`)
+		case "/pypi/flask/json":
+			fmt.Fprint(w, `{"releases": {"1.0.dev1": [{}], "1.0a1.dev2": [{}], "1.0a1": [{}]}}`)
+		case "/pypi/django-rest-framework/json":
+			fmt.Fprint(w, `{"releases": {"0.1": [{}], "0.1.post1": [{}], "0.2b1": [{}]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestPyPI(t *testing.T) {
+	srv := server()
+	defer srv.Close()
+
+	cases := []struct {
+		name       string
+		line       string
+		prerelease policy.Prerelease
+		newLine    string
+	}{
+		{
+			name:    "pin with a marker",
+			line:    `requests[socks]==2.30.0 ; python_version >= "3.8"  # http`,
+			newLine: `requests[socks]==2.32.1 ; python_version >= "3.8"  # http`,
+		},
+		{
+			name:       "pre-release pin moves on to the release",
+			line:       "Django_Rest.Framework == 0.1a2",
+			prerelease: policy.Always,
+			newLine:    "Django_Rest.Framework == 0.2b1",
+		},
+	}
+
+	p := New(srv.URL)
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			if !assert.True(t, p.Match("requirements.txt", test.line), test.name) {
+				return
+			}
+
+			current, versions, err := p.Versions(test.line)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			next := policy.Select(policy.Major, test.prerelease, current, versions)
+			if !assert.NotNil(t, next, test.name) {
+				return
+			}
+
+			assert.Equal(t, test.newLine, p.Rewrite(test.line, current, next), test.name)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	p := New("")
+
+	cases := []struct {
+		file string
+		line string
+		want bool
+	}{
+		{file: "requirements.txt", line: "flask==2.3.2", want: true},
+		{file: "requirements/constraints-prod.in", line: "flask==2.3.2", want: true},
+		{file: "constraints.txt", line: "flask == 2.3", want: true},
+		{file: "requirements.txt", line: "flask>=2.3.2"},
+		{file: "requirements.txt", line: "flask==2.3.2,<3"},
+		{file: "requirements.txt", line: "# flask==2.3.2"},
+		{file: "setup.cfg", line: "flask==2.3.2"},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.want, p.Match(test.file, test.line), test.file+": "+test.line)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	for version, want := range map[string]string{
+		"1.2.3":       "1.2.3",
+		"2.0":         "2.0.0",
+		"1.0rc1":      "1.0.0-3.1.1",
+		"1.0b2.dev3":  "1.0.0-2.2.0.3",
+		"2.0.dev3":    "2.0.0-0.3",
+		"2023.7.22":   "2023.7.22",
+		"1.0.post1":   "",
+		"1!2.0":       "",
+		"1.0+local.1": "",
+	} {
+		got, err := Version(version)
+		if want == "" {
+			assert.NotNil(t, err, version)
+
+			continue
+		}
+
+		if assert.Nil(t, err, version) {
+			assert.Equal(t, want, got.String(), version)
+		}
+	}
+}
+
+func TestVersionOrder(t *testing.T) {
+	// The PEP 440 example order of the releases of a version.
+	order := []string{"1.0.dev456", "1.0a1.dev1", "1.0a1", "1.0a2", "1.0b1.dev456", "1.0b2", "1.0rc1", "1.0"}
+
+	for i := 1; i < len(order); i++ {
+		prev, err := Version(order[i-1])
+		if !assert.Nil(t, err, order[i-1]) {
+			return
+		}
+
+		next, err := Version(order[i])
+		if !assert.Nil(t, err, order[i]) {
+			return
+		}
+
+		assert.True(t, prev.LessThan(next), order[i-1]+" < "+order[i])
+	}
+}
+
+func TestPyPIDevReleases(t *testing.T) {
+	srv := server()
+	defer srv.Close()
+
+	p := New(srv.URL)
+
+	current, versions, err := p.Versions("flask==1.0a1")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Nil(t, policy.Select(policy.Major, policy.SameTrack, current, versions))
+}
@@ -0,0 +1,210 @@
+package rubygems
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultURL = "https://rubygems.org"
+
+func init() {
+	updater.Register("rubygems", 250, func(updater.Options) updater.Updater {
+		return New(defaultURL)
+	})
+}
+
+// RubyGems updates the gem pins of Gemfiles. Exact versions and pessimistic
+// ~> requirements are updated, keeping the operator and, for ~>, the number
+// of parts written.
+type RubyGems struct {
+	baseURL string
+	client  *http.Client
+
+	mu sync.Mutex
+	// numbers maps the versions of every gem looked up to the gem versions
+	// they stand for.
+	numbers map[string]map[string]string
+}
+
+// New returns an updater for the RubyGems API at baseURL.
+func New(baseURL string) *RubyGems {
+	return &RubyGems{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  http.DefaultClient,
+		numbers: map[string]map[string]string{},
+	}
+}
+
+var (
+	// gemRegex matches gem "name", "requirement" with a single requirement,
+	// followed by options or a comment.
+	gemRegex = regexp.MustCompile(`^\s*gem\s*\(?\s*["']([\w.-]+)["']\s*,\s*["'](~>\s*|=\s*)?(\d+(?:\.[0-9A-Za-z]+)*)["']\s*(?:,\s*[^"'\s].*|\)?\s*#.*|\)?\s*)$`)
+	// numberRegex splits a gem version into up to four release parts and
+	// the pre-release that starts at the first part with a letter.
+	numberRegex = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.(\d+))?((?:\.\d*[A-Za-z][0-9A-Za-z]*)(?:\.[0-9A-Za-z]+)*)?$`)
+)
+
+// Scan reports whether file is a Gemfile.
+func (r *RubyGems) Scan(file string) bool {
+	base := filepath.Base(file)
+
+	return base == "Gemfile" || base == "gems.rb" || strings.HasSuffix(base, ".gemfile")
+}
+
+func (r *RubyGems) Match(file, line string) bool {
+	return r.Scan(file) && gemRegex.MatchString(line)
+}
+
+// Version returns the semantic version of a gem version. Gems often have a
+// fourth part, like the 3 of rails 7.0.4.3, which is folded into the patch
+// as 7.0.4003 so that it still counts as a patch release. 7.1.0.rc1 is the
+// pre-release 7.1.0-rc1.
+func Version(number string) (*semver.Version, error) {
+	matches := numberRegex.FindStringSubmatch(number)
+	if matches == nil {
+		return nil, fmt.Errorf("cannot convert %s to a semantic version", number)
+	}
+
+	parts := make([]int64, 4)
+
+	for i, part := range matches[1:5] {
+		if part == "" {
+			continue
+		}
+
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || (i == 3 && n >= 1000) {
+			return nil, fmt.Errorf("cannot convert %s to a semantic version", number)
+		}
+
+		parts[i] = n
+	}
+
+	ret := fmt.Sprintf("%d.%d.%d", parts[0], parts[1], parts[2]*1000+parts[3])
+
+	if pre := strings.TrimPrefix(matches[5], "."); pre != "" {
+		ret += "-" + pre
+	}
+
+	return semver.NewVersion(ret)
+}
+
+func (r *RubyGems) Versions(line string) (*semver.Version, []*semver.Version, error) {
+	matches := gemRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, nil, fmt.Errorf("cannot find gem in %s", line)
+	}
+
+	current, err := Version(matches[3])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	numbers, err := r.gemVersions(matches[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	originals := map[string]string{}
+
+	var ret []*semver.Version
+
+	for _, number := range numbers {
+		version, err := Version(number)
+		if err != nil {
+			continue
+		}
+
+		originals[version.String()] = number
+		ret = append(ret, version)
+	}
+
+	r.mu.Lock()
+	r.numbers[matches[1]] = originals
+	r.mu.Unlock()
+
+	return current, ret, nil
+}
+
+// gemVersions returns the version numbers of the gem name, once for every
+// platform it is built for.
+func (r *RubyGems) gemVersions(name string) ([]string, error) {
+	url := fmt.Sprintf("%s/api/v1/versions/%s.json", r.baseURL, name)
+
+	resp, err := r.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get %s: %s", url, resp.Status)
+	}
+
+	var versions []struct {
+		Number string `json:"number"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&versions)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %w", url, err)
+	}
+
+	seen := map[string]struct{}{}
+
+	var ret []string
+
+	for _, v := range versions {
+		if _, ok := seen[v.Number]; ok {
+			continue
+		}
+
+		seen[v.Number] = struct{}{}
+		ret = append(ret, v.Number)
+	}
+
+	log.WithFields(log.Fields{
+		"gem": name,
+		"len": len(ret),
+	}).Debug("found gem versions")
+
+	return ret, nil
+}
+
+// Rewrite sets the requirement of the gem to next. A ~> requirement keeps
+// the number of parts it was written with, so ~> 7.0 becomes ~> 7.1.
+func (r *RubyGems) Rewrite(line string, current, next *semver.Version) string {
+	matches := gemRegex.FindStringSubmatchIndex(line)
+	if matches == nil {
+		return line
+	}
+
+	r.mu.Lock()
+	number, ok := r.numbers[line[matches[2]:matches[3]]][next.String()]
+	r.mu.Unlock()
+
+	if !ok {
+		return line
+	}
+
+	written := line[matches[6]:matches[7]]
+
+	if matches[4] >= 0 && strings.HasPrefix(line[matches[4]:matches[5]], "~>") {
+		parts := strings.Split(number, ".")
+		if n := strings.Count(written, ".") + 1; n < len(parts) {
+			number = strings.Join(parts[:n], ".")
+		}
+	}
+
+	return line[:matches[6]] + number + line[matches[7]:]
+}
@@ -0,0 +1,129 @@
+package rubygems
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhristof/bump/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/versions/rails.json":
+			fmt.Fprint(w, `[
+				{"number": "7.1.0.rc1"},
+				{"number": "7.0.8"},
+				{"number": "7.0.4.3"},
+				{"number": "7.0.4"},
+				{"number": "6.1.7.6"}
+			]`)
+		case "/api/v1/versions/nokogiri.json":
+			fmt.Fprint(w, `[
+				{"number": "1.15.4", "platform": "ruby"},
+				{"number": "1.15.4", "platform": "x86_64-linux"},
+				{"number": "1.15.3", "platform": "ruby"}
+			]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestRubyGems(t *testing.T) {
+	srv := server()
+	defer srv.Close()
+
+	cases := []struct {
+		name    string
+		line    string
+		level   policy.Level
+		newLine string
+	}{
+		{
+			name:    "exact pin with four parts",
+			line:    `gem "rails", "7.0.4.3"`,
+			level:   policy.Major,
+			newLine: `gem "rails", "7.0.8"`,
+		},
+		{
+			name:    "patch release in the fourth part",
+			line:    `gem 'rails', '6.1.7'`,
+			level:   policy.Patch,
+			newLine: `gem 'rails', '6.1.7.6'`,
+		},
+		{
+			name:    "pessimistic requirement keeps its parts",
+			line:    `  gem 'nokogiri', '~> 1.14', require: false # html`,
+			level:   policy.Major,
+			newLine: `  gem 'nokogiri', '~> 1.15', require: false # html`,
+		},
+	}
+
+	r := New(srv.URL)
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			if !assert.True(t, r.Match("Gemfile", test.line), test.name) {
+				return
+			}
+
+			current, versions, err := r.Versions(test.line)
+			if !assert.Nil(t, err, test.name) {
+				return
+			}
+
+			next := policy.Select(test.level, policy.SameTrack, current, versions)
+			if !assert.NotNil(t, next, test.name) {
+				return
+			}
+
+			assert.Equal(t, test.newLine, r.Rewrite(test.line, current, next), test.name)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	r := New("")
+
+	cases := []struct {
+		file string
+		line string
+		want bool
+	}{
+		{file: "Gemfile", line: `gem "puma", "6.3.0"`, want: true},
+		{file: "gemfiles/rails_7.gemfile", line: `gem("rails", "= 7.0.4")`, want: true},
+		{file: "Gemfile", line: `gem "puma", ">= 5.0", "< 7"`},
+		{file: "Gemfile", line: `gem "puma"`},
+		{file: "Gemfile.lock", line: `gem "puma", "6.3.0"`},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.want, r.Match(test.file, test.line), test.file+": "+test.line)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	for number, want := range map[string]string{
+		"7.0.4":     "7.0.4000",
+		"7.0.4.3":   "7.0.4003",
+		"7.1":       "7.1.0",
+		"7.1.0.rc1": "7.1.0-rc1",
+		"1.0.0.0.1": "",
+		"latest":    "",
+	} {
+		got, err := Version(number)
+		if want == "" {
+			assert.NotNil(t, err, number)
+
+			continue
+		}
+
+		if assert.Nil(t, err, number) {
+			assert.Equal(t, want, got.String(), number)
+		}
+	}
+}
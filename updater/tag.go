@@ -0,0 +1,85 @@
+package updater
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// tagRegex splits an image tag into a version with up to three parts, an
+// optional pre-release and the variant that follows, like the -slim-bookworm
+// of 3.11.4-slim-bookworm.
+var tagRegex = regexp.MustCompile(`^(v?\d+(?:\.\d+){0,2}(?:-(?i:alpha|beta|rc|pre|dev)[0-9A-Za-z.]*)?)([-_][0-9A-Za-z._-]*)?$`)
+
+// SplitTag returns the version of a container image tag and its variant
+// suffix, which is empty for plain version tags. 1.25-alpine is version
+// 1.25 of the -alpine variant and 2.0.0-rc.1 is a pre-release with no
+// variant.
+func SplitTag(tag string) (*semver.Version, string, error) {
+	matches := tagRegex.FindStringSubmatch(tag)
+	if matches == nil {
+		return nil, "", fmt.Errorf("cannot find version in tag %s", tag)
+	}
+
+	version, err := semver.NewVersion(matches[1])
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot parse tag %s: %w", tag, err)
+	}
+
+	return version, matches[2], nil
+}
+
+// TagVersions returns the versions of the tags of the given variant.
+func TagVersions(tags []string, variant string) []*semver.Version {
+	var ret []*semver.Version
+
+	for _, tag := range tags {
+		version, v, err := SplitTag(tag)
+		if err != nil || v != variant {
+			continue
+		}
+
+		ret = append(ret, version)
+	}
+
+	return ret
+}
+
+// ReplaceTag returns line with the tag of image, written as name:tag,
+// set to next followed by the variant of the tag.
+func ReplaceTag(line, image string, next *semver.Version) string {
+	i := strings.LastIndex(image, ":")
+	if i < 0 {
+		return line
+	}
+
+	_, variant, err := SplitTag(image[i+1:])
+	if err != nil {
+		return line
+	}
+
+	return strings.ReplaceAll(line, image, image[:i+1]+next.Original()+variant)
+}
+
+var digestRegex = regexp.MustCompile(`@sha256:[0-9a-f]{64}`)
+
+// HasDigest reports whether line pins an image to a digest.
+func HasDigest(line string) bool {
+	return digestRegex.MatchString(line)
+}
+
+// Digest returns the first digest an image is pinned to in line, or an
+// empty string.
+func Digest(line string) string {
+	return strings.TrimPrefix(digestRegex.FindString(line), "@")
+}
+
+// SetDigest returns line with image, written as name:tag, pinned to digest.
+// The digest that follows image, if any, is replaced.
+func SetDigest(line, image, digest string) string {
+	r := regexp.MustCompile(`(` + regexp.QuoteMeta(image) + `)(?:@sha256:[0-9a-f]{64})?([^\w.-]|$)`)
+
+	return r.ReplaceAllString(line, "${1}@"+digest+"${2}")
+}
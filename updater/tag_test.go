@@ -0,0 +1,144 @@
+package updater_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mhristof/bump/updater"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTag(t *testing.T) {
+	cases := []struct {
+		tag     string
+		version string
+		variant string
+		err     bool
+	}{
+		{tag: "3.18", version: "3.18"},
+		{tag: "v1.2.3", version: "v1.2.3"},
+		{tag: "1.25-alpine", version: "1.25", variant: "-alpine"},
+		{tag: "3.11.4-slim-bookworm", version: "3.11.4", variant: "-slim-bookworm"},
+		{tag: "20-alpine3.18", version: "20", variant: "-alpine3.18"},
+		{tag: "2.0.0-rc.1", version: "2.0.0-rc.1"},
+		{tag: "2.0.0-rc1-alpine", version: "2.0.0-rc1", variant: "-alpine"},
+		{tag: "1.2.3_linux", version: "1.2.3", variant: "_linux"},
+		{tag: "latest", err: true},
+		{tag: "bookworm-slim", err: true},
+		{tag: "1.2.3.4", err: true},
+	}
+
+	for _, test := range cases {
+		t.Run(test.tag, func(t *testing.T) {
+			version, variant, err := updater.SplitTag(test.tag)
+			if test.err {
+				assert.NotNil(t, err, test.tag)
+
+				return
+			}
+
+			if !assert.Nil(t, err, test.tag) {
+				return
+			}
+
+			assert.Equal(t, test.version, version.Original(), test.tag)
+			assert.Equal(t, test.variant, variant, test.tag)
+		})
+	}
+}
+
+func TestTagVersions(t *testing.T) {
+	tags := []string{"3.12", "3.12-alpine", "3.12-slim-bookworm", "3.13-alpine", "alpine", "latest", "3.13-alpine3.19"}
+
+	originals := func(versions []*semver.Version) []string {
+		ret := make([]string, len(versions))
+		for i, v := range versions {
+			ret[i] = v.Original()
+		}
+
+		return ret
+	}
+
+	assert.Equal(t, []string{"3.12"}, originals(updater.TagVersions(tags, "")))
+	assert.Equal(t, []string{"3.12", "3.13"}, originals(updater.TagVersions(tags, "-alpine")))
+	assert.Equal(t, []string{"3.12"}, originals(updater.TagVersions(tags, "-slim-bookworm")))
+}
+
+func TestReplaceTag(t *testing.T) {
+	cases := []struct {
+		name  string
+		line  string
+		image string
+		next  string
+		want  string
+	}{
+		{
+			name:  "keeps the variant",
+			line:  `image: "library/python:3.11.4-slim-bookworm"`,
+			image: "library/python:3.11.4-slim-bookworm",
+			next:  "3.12.1",
+			want:  `image: "library/python:3.12.1-slim-bookworm"`,
+		},
+		{
+			name:  "variant with the same digits as the version",
+			line:  "FROM library/node:20-alpine3.20",
+			image: "library/node:20-alpine3.20",
+			next:  "22",
+			want:  "FROM library/node:22-alpine3.20",
+		},
+		{
+			name:  "plain",
+			line:  "ghcr.io/org/app:v1.2.3",
+			image: "ghcr.io/org/app:v1.2.3",
+			next:  "v1.3.0",
+			want:  "ghcr.io/org/app:v1.3.0",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, updater.ReplaceTag(test.line, test.image, semver.MustParse(test.next)), test.name)
+		})
+	}
+}
+
+func TestSetDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	old := "sha256:" + strings.Repeat("0", 64)
+
+	cases := []struct {
+		name  string
+		line  string
+		image string
+		want  string
+	}{
+		{
+			name:  "adds the digest",
+			line:  `image: "ghcr.io/org/app:v1.2.3"`,
+			image: "ghcr.io/org/app:v1.2.3",
+			want:  `image: "ghcr.io/org/app:v1.2.3@` + digest + `"`,
+		},
+		{
+			name:  "replaces the digest",
+			line:  "FROM library/alpine:3.19@" + old + " AS base",
+			image: "library/alpine:3.19",
+			want:  "FROM library/alpine:3.19@" + digest + " AS base",
+		},
+		{
+			name:  "leaves longer tags alone",
+			line:  "library/alpine:3.19.1",
+			image: "library/alpine:3.19",
+			want:  "library/alpine:3.19.1",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, updater.SetDigest(test.line, test.image, digest), test.name)
+		})
+	}
+
+	assert.True(t, updater.HasDigest("alpine:3.19@"+digest))
+	assert.False(t, updater.HasDigest("alpine:3.19"))
+}